@@ -4,7 +4,11 @@ go 1.15
 
 require (
 	github.com/backube/scribe v0.1.0
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/go-logr/logr v0.4.0 // indirect
+	github.com/operator-framework/operator-lib v0.1.0
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.0
@@ -16,6 +20,7 @@ require (
 	k8s.io/klog/v2 v2.4.0
 	k8s.io/kubectl v0.20.4
 	sigs.k8s.io/controller-runtime v0.6.2
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace github.com/backube/scribectl => /home/somalley/code/gowork/src/github.com/backube/scribectl
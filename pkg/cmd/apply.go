@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeApplyLong = templates.LongDesc(`
+Set up the replication pairs described in a ReplicationPlan file. For each pair, the ReplicationDestination
+is created if it does not exist. With rsync, the ReplicationSource then connects to the address the
+ReplicationDestination publishes, unless the plan sets one, and the SSH keys secret is copied to the source
+namespace. The ReplicationSource is created last if it does not exist.
+
+Objects that exist are compared with the plan. The spec fields set in the plan are applied as a merge patch
+when they drifted, and the diff is printed; fields the plan does not set are left as they are, so applying
+the same plan again changes nothing. A summary reports what was created, configured or unchanged.
+
+A ReplicationPlan lists pairs with the values of the flags of new-source and new-destination. The kube
+context, cluster and namespace of each side default to the flags of the command. Unlike --source-cron-spec,
+the schedule of a source has no default: a source without a schedule syncs continuously, starting a sync
+as soon as the previous one completes:
+
+    apiVersion: scribectl.backube/v1alpha1
+    kind: ReplicationPlan
+    pairs:
+    - name: mysql
+      source:
+        kubeContext: source-admin
+        namespace: source
+        pvc: mysql-pv-claim
+        copyMethod: Snapshot
+        schedule: "*/5 * * * *"
+      destination:
+        kubeContext: dest-admin
+        namespace: dest
+        copyMethod: Snapshot
+        capacity: 2Gi
+        accessMode: ReadWriteOnce
+        serviceType: LoadBalancer
+    - name: uploads
+      mover: rclone
+      rclone:
+        config: rclone-secret
+        configSection: aws-s3-bucket
+        destPath: scribe-uploads
+      source:
+        namespace: uploads
+        pvc: uploads
+        copyMethod: Clone
+      destination:
+        namespace: uploads-copy
+        copyMethod: Snapshot
+        pvc: uploads
+`)
+	scribeApplyExample = templates.Examples(`
+        # Create or update the pairs of the plan.
+        scribe apply -f plan.yaml
+
+        # Show what would be created or changed without changing anything.
+        scribe apply -f plan.yaml --dry-run=client
+    `)
+)
+
+type applyOptions struct {
+	scribeOptions  scribeOptions
+	Filename       string
+	AddressTimeout time.Duration
+	DryRunStrategy kcmdutil.DryRunStrategy
+	genericclioptions.IOStreams
+}
+
+// applyResult is the outcome of applying one object of a pair.
+type applyResult struct {
+	pair      string
+	object    string
+	context   string
+	namespace string
+	result    string
+}
+
+func NewApplyOptions(streams genericclioptions.IOStreams) *applyOptions {
+	return &applyOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeApply(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewApplyOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "apply -f FILENAME [OPTIONS]",
+		Short:   i18n.T("Create or update the replication pairs described in a ReplicationPlan file."),
+		Long:    fmt.Sprintf(scribeApplyLong),
+		Example: fmt.Sprintf(scribeApplyExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Apply())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *applyOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Filename, "filename", "f", o.Filename, "the ReplicationPlan file to apply, '-' to read it from stdin.")
+	flags.DurationVar(&o.AddressTimeout, "address-timeout", 5*time.Minute, "how long to wait for a new ReplicationDestination to publish its address.")
+	kcmdutil.AddDryRunFlag(cmd)
+	cmd.MarkFlagRequired("filename")
+}
+
+func (o *applyOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+// Complete reads the dry-run strategy. The clients are built for each pair of the plan.
+func (o *applyOptions) Complete(cmd *cobra.Command) error {
+	var err error
+	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
+	return err
+}
+
+// Validate validates apply options.
+func (o *applyOptions) Validate() error {
+	if len(o.Filename) == 0 {
+		return fmt.Errorf("must provide -f, the ReplicationPlan file to apply")
+	}
+	return nil
+}
+
+// Apply applies each pair of the plan, then prints a summary. A failed pair does not stop the others.
+func (o *applyOptions) Apply() error {
+	ctx := context.Background()
+	plan, err := loadReplicationPlan(o.Filename)
+	if err != nil {
+		return err
+	}
+	results := []applyResult{}
+	failed := 0
+	for i := range plan.Pairs {
+		pair := &plan.Pairs[i]
+		pairResults, err := o.applyPair(ctx, pair)
+		results = append(results, pairResults...)
+		if err != nil {
+			klog.Errorf("pair %s: %v", pair.Name, err)
+			results = append(results, applyResult{pair: pair.Name, result: "failed"})
+			failed++
+		}
+	}
+	if err := printApplyResults(o.Out, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pairs failed to apply", failed, len(plan.Pairs))
+	}
+	return nil
+}
+
+// applyPair applies the ReplicationDestination of the pair, then its ReplicationSource.
+func (o *applyOptions) applyPair(ctx context.Context, pair *replicationPlanPair) ([]applyResult, error) {
+	so := pair.scribeOptions(o.scribeOptions)
+	if err := so.Complete(); err != nil {
+		return nil, err
+	}
+	p := &printOptions{DryRunStrategy: o.DryRunStrategy}
+	results := []applyResult{}
+
+	d := pair.destinationOptions(so, o.IOStreams)
+	plannedRD, err := d.newReplicationDestination()
+	if err != nil {
+		return results, err
+	}
+	result, err := applyPlanned(ctx, so.DestinationClient, p, plannedRD, &scribev1alpha1.ReplicationDestination{}, &scribev1alpha1.ReplicationDestination{}, d.defaultedFields(), o.Out)
+	if err != nil {
+		return results, err
+	}
+	results = append(results, applyResult{pair.Name, "replicationdestination/" + d.DestName, so.destKubeContext, d.DestNamespace, result})
+
+	s := pair.sourceOptions(so, o.IOStreams)
+	if s.Mover != "rclone" {
+		if err := o.completeRsyncSource(ctx, s, d.DestName, result == "created"); err != nil {
+			return results, err
+		}
+	}
+	plannedRS, err := s.newReplicationSource()
+	if err != nil {
+		return results, err
+	}
+	result, err = applyPlanned(ctx, so.SourceClient, p, plannedRS, &scribev1alpha1.ReplicationSource{}, &scribev1alpha1.ReplicationSource{}, s.defaultedFields(), o.Out)
+	if err != nil {
+		return results, err
+	}
+	return append(results, applyResult{pair.Name, "replicationsource/" + s.SourceName, so.sourceKubeContext, s.SourceNamespace, result}), nil
+}
+
+// completeRsyncSource fills in the address and SSH keys secret of the ReplicationSource from its
+// ReplicationDestination and copies the secret to the source namespace.
+func (o *applyOptions) completeRsyncSource(ctx context.Context, s *sourceOptions, destName string, destCreated bool) error {
+	if o.DryRunStrategy != kcmdutil.DryRunNone && destCreated {
+		// the ReplicationDestination was not persisted, so it publishes nothing
+		klog.V(0).Infof("the address of ReplicationDestination %s is only known once it is created", destName)
+		if len(s.sshKeysSecretOptions.SSHKeysSecret) == 0 {
+			s.sshKeysSecretOptions.SSHKeysSecret = "scribe-rsync-dest-src-" + destName
+		}
+		return nil
+	}
+	s.FromDestination = destName
+	s.AddressTimeout = o.AddressTimeout
+	if err := s.completeFromDestination(); err != nil {
+		return err
+	}
+	if o.DryRunStrategy != kcmdutil.DryRunNone {
+		return nil
+	}
+	sk := &sshKeysSecretOptions{
+		scribeOptions: s.scribeOptions,
+		SSHKeysSecret: s.sshKeysSecretOptions.SSHKeysSecret,
+	}
+	_, err := sk.syncSSHSecretIfMissing(ctx)
+	return err
+}
+
+// applyPlanned creates the planned object if it does not exist, or patches the live object with the
+// fields of the plan if they drifted. live and merged are empty objects of the planned type. It returns
+// whether the object was created, configured or unchanged. The defaulted fields are only set on creation.
+func applyPlanned(ctx context.Context, c client.Client, p *printOptions, planned, live, merged runtime.Object, defaulted [][]string, out io.Writer) (string, error) {
+	nsName, name, err := plannedName(planned)
+	if err != nil {
+		return "", err
+	}
+	err = c.Get(ctx, nsName, live)
+	if kerrors.IsNotFound(err) {
+		return "created", p.create(ctx, c, planned, out)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := mergePlanned(live, planned, merged, defaulted); err != nil {
+		return "", err
+	}
+	diff, err := objectDiff(name, "live", "plan", live, merged)
+	if err != nil {
+		return "", err
+	}
+	if len(diff) == 0 {
+		return "unchanged", nil
+	}
+	fmt.Fprint(out, diff)
+	return "configured", p.patch(ctx, c, merged, live, out)
+}
+
+// plannedName returns the namespaced name of the planned object and its name prefixed with its kind,
+// as printed in diffs.
+func plannedName(planned runtime.Object) (types.NamespacedName, string, error) {
+	accessor, err := meta.Accessor(planned)
+	if err != nil {
+		return types.NamespacedName{}, "", err
+	}
+	nsName := types.NamespacedName{Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+	return nsName, strings.ToLower(planned.GetObjectKind().GroupVersionKind().Kind) + "/" + nsName.Name, nil
+}
+
+func printApplyResults(out io.Writer, results []applyResult) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "PAIR\tOBJECT\tCONTEXT\tNAMESPACE\tRESULT")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.pair, valueOrNone(&r.object), valueOrNone(&r.context), valueOrNone(&r.namespace), r.result)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// scribeConfigEnv names the environment variable holding the path of the scribe config.
+	scribeConfigEnv = "SCRIBE_CONFIG"
+	// profilesKey is the key of the scribe config holding the named profiles.
+	profilesKey = "profiles"
+)
+
+var (
+	// configFile is the path of the scribe config passed with --config.
+	configFile string
+	// configProfile is the profile of the scribe config selected with --profile.
+	configProfile string
+)
+
+var (
+	scribeConfigLong = templates.LongDesc(`
+Inspect the scribe config. The scribe config sets the flags of every command that are not passed on the
+command line. It is the file passed with --config, or else the file in the SCRIBE_CONFIG environment
+variable, or else the first scribe-config file found in the current directory, $XDG_CONFIG_HOME/scribe/
+($HOME/.config/scribe/ if XDG_CONFIG_HOME is not set) and $HOME/.scribe/.
+
+The scribe config can hold named profiles under the profiles key, for instance one per application
+replicated between the same clusters. The profile selected with --profile sets the flags it has, and the
+top level of the scribe config sets the other flags for all profiles:
+
+    dest-kube-context: dest-admin
+    source-kube-context: source-admin
+    dest-copy-method: Snapshot
+    source-copy-method: Snapshot
+    profiles:
+      mysql:
+        source-namespace: db
+        source-pvc: mysql-pv-claim
+        dest-namespace: db-copy
+      uploads:
+        source-namespace: web
+        source-pvc: uploads
+        dest-namespace: web-copy
+        source-copy-method: Clone
+`)
+	scribeConfigViewLong = templates.LongDesc(`
+Show which scribe config file is loaded and the values it sets, with where each value comes from: the top
+level of the file or, with --profile, the profile. Keys that are not the name of a flag of any command are
+reported, as they are ignored.
+
+With a COMMAND, show the value each flag of the command gets when it is not passed on the command line,
+from the scribe config or else the default of the flag.
+`)
+	scribeConfigViewExample = templates.Examples(`
+        # Show the scribe config found in the search path.
+        scribe config view
+
+        # Show the scribe config of another file.
+        scribe config view --config ~/replications/mysql.yaml
+
+        # Show the values the commands get with the profile mysql.
+        scribe config view --profile mysql
+
+        # Show the value of each flag of new-source and where it comes from.
+        scribe config view new-source
+    `)
+)
+
+// configPaths returns the directories where the scribe config is looked up, in order.
+func configPaths() []string {
+	paths := []string{"."}
+	home, err := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if len(configHome) == 0 && err == nil {
+		configHome = filepath.Join(home, ".config")
+	}
+	if len(configHome) > 0 {
+		paths = append(paths, filepath.Join(configHome, "scribe"))
+	}
+	if err == nil {
+		paths = append(paths, filepath.Join(home, ".scribe"))
+	}
+	return paths
+}
+
+// configSource returns the scribe config set with --config or SCRIBE_CONFIG and how it was set. The file
+// is empty when the scribe config is looked up in the search path.
+func configSource() (string, string) {
+	if len(configFile) > 0 {
+		return configFile, "--config"
+	}
+	if file := os.Getenv(scribeConfigEnv); len(file) > 0 {
+		return file, scribeConfigEnv
+	}
+	return "", "the search path"
+}
+
+// readConfig reads the scribe config into v. A file set with --config or SCRIBE_CONFIG must exist, while
+// finding no file in the search path is not an error. The profile selected with --profile must exist.
+func readConfig(v *viper.Viper) error {
+	v.SetConfigType("yaml")
+	if file, _ := configSource(); len(file) > 0 {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return err
+		}
+		return checkProfile(v)
+	}
+	v.SetConfigName(scribeConfig)
+	for _, path := range configPaths() {
+		v.AddConfigPath(path)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return err
+		}
+	}
+	return checkProfile(v)
+}
+
+// checkProfile returns an error if the profile selected with --profile is not in the scribe config.
+func checkProfile(v *viper.Viper) error {
+	if len(configProfile) == 0 {
+		return nil
+	}
+	if strings.Contains(configProfile, ".") {
+		return fmt.Errorf("invalid profile %s, profile names cannot contain '.'", configProfile)
+	}
+	if len(v.ConfigFileUsed()) == 0 {
+		return fmt.Errorf("profile %s not found, no %s found in %s", configProfile, scribeConfig, strings.Join(configPaths(), ", "))
+	}
+	if !v.IsSet(profilesKey + "." + configProfile) {
+		return fmt.Errorf("profile %s not found in %s, the profiles are: %s", configProfile, v.ConfigFileUsed(), strings.Join(profileNames(v), ", "))
+	}
+	return nil
+}
+
+// profileNames returns the names of the profiles of the scribe config.
+func profileNames(v *viper.Viper) []string {
+	names := []string{}
+	for name := range v.GetStringMap(profilesKey) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configValue returns the value of the key in the profile selected with --profile, or else at the top
+// level of the scribe config, with where it was found.
+func configValue(v *viper.Viper, key string) (interface{}, string, bool) {
+	if len(configProfile) > 0 {
+		if profileKey := profilesKey + "." + configProfile + "." + key; v.IsSet(profileKey) {
+			return v.Get(profileKey), "profile " + configProfile, true
+		}
+	}
+	if key != profilesKey && v.IsSet(key) {
+		return v.Get(key), "top level", true
+	}
+	return nil, "", false
+}
+
+// bindConfig calls bind to bind flags to the command and sets them from the scribe config once the
+// command line is parsed, so that --config is known and the flags passed take precedence.
+func bindConfig(cmd *cobra.Command, v *viper.Viper, bind func()) {
+	flags := cmd.Flags()
+	bound := map[string]bool{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		bound[f.Name] = true
+	})
+	bind()
+	names := []string{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !bound[f.Name] {
+			names = append(names, f.Name)
+		}
+	})
+	preRun := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if preRun != nil {
+			if err := preRun(cmd, args); err != nil {
+				return err
+			}
+		}
+		if err := readConfig(v); err != nil {
+			return err
+		}
+		flags := cmd.Flags()
+		for _, name := range names {
+			if flags.Changed(name) {
+				continue
+			}
+			if value, source, found := configValue(v, name); found {
+				if err := flags.Set(name, fmt.Sprintf("%v", value)); err != nil {
+					return fmt.Errorf("invalid %s in the %s of %s: %v", name, source, v.ConfigFileUsed(), err)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// NewCmdScribeConfig implements the config command and its subcommands.
+func NewCmdScribeConfig(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config SUBCOMMAND",
+		Short: i18n.T("Inspect the scribe config."),
+		Long:  fmt.Sprintf(scribeConfigLong),
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(NewCmdScribeConfigView(streams))
+	return cmd
+}
+
+type configViewOptions struct {
+	genericclioptions.IOStreams
+}
+
+func NewConfigViewOptions(streams genericclioptions.IOStreams) *configViewOptions {
+	return &configViewOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeConfigView(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewConfigViewOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "view [COMMAND]",
+		Short:   i18n.T("Show the scribe config file loaded and the values it sets."),
+		Long:    fmt.Sprintf(scribeConfigViewLong),
+		Example: fmt.Sprintf(scribeConfigViewExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.View(cmd.Root(), args))
+		},
+	}
+	return cmd
+}
+
+// View prints the loaded scribe config, reporting the keys that are not flags of a command of root, or
+// the values of the flags of the command of root named by args.
+func (o *configViewOptions) View(root *cobra.Command, args []string) error {
+	var cmd *cobra.Command
+	if len(args) > 0 {
+		c, _, err := root.Find(args)
+		if err != nil || c == root {
+			return fmt.Errorf("unknown command %q for %s", strings.Join(args, " "), root.Name())
+		}
+		cmd = c
+	}
+	v := viper.New()
+	if err := readConfig(v); err != nil {
+		return err
+	}
+	file, source := configSource()
+	if len(v.ConfigFileUsed()) == 0 {
+		fmt.Fprintf(o.Out, "Config file: <none>, no %s found in %s\n", scribeConfig, strings.Join(configPaths(), ", "))
+		if cmd == nil {
+			return nil
+		}
+	} else if len(file) == 0 {
+		fmt.Fprintf(o.Out, "Config file: %s (found in %s: %s)\n", v.ConfigFileUsed(), source, strings.Join(configPaths(), ", "))
+	} else {
+		fmt.Fprintf(o.Out, "Config file: %s (set with %s)\n", v.ConfigFileUsed(), source)
+	}
+	if cmd != nil {
+		return o.viewCommand(v, cmd)
+	}
+	profiles := profileNames(v)
+	switch {
+	case len(configProfile) > 0:
+		fmt.Fprintf(o.Out, "Profile: %s\n", configProfile)
+	case len(profiles) > 0:
+		fmt.Fprintf(o.Out, "Profiles: %s (select one with --profile)\n", strings.Join(profiles, ", "))
+	}
+	keys := configKeys(v)
+	if len(keys) == 0 {
+		return nil
+	}
+	flagNames := commandFlagNames(root)
+	fmt.Fprintln(o.Out)
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, key := range keys {
+		value, source, _ := configValue(v, key)
+		fmt.Fprintf(w, "%s\t%v\t%s\n", key, value, source)
+		if !flagNames[key] {
+			klog.Warningf("%s is not a flag of any command and is ignored", key)
+		}
+	}
+	return w.Flush()
+}
+
+// viewCommand prints the value of each flag of the command that is not passed on the command line, with
+// where it comes from.
+func (o *configViewOptions) viewCommand(v *viper.Viper, cmd *cobra.Command) error {
+	if len(configProfile) > 0 {
+		fmt.Fprintf(o.Out, "Profile: %s\n", configProfile)
+	}
+	fmt.Fprintln(o.Out)
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "FLAG\tVALUE\tSOURCE")
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		value, source, found := configValue(v, f.Name)
+		if !found {
+			value, source = f.DefValue, "default"
+		}
+		fmt.Fprintf(w, "--%s\t%v\t%s\n", f.Name, value, source)
+	})
+	return w.Flush()
+}
+
+// configKeys returns the sorted keys of the top level of the scribe config and of the profile selected
+// with --profile.
+func configKeys(v *viper.Viper) []string {
+	profilePrefix := profilesKey + "." + strings.ToLower(configProfile) + "."
+	found := map[string]bool{}
+	for _, key := range v.AllKeys() {
+		switch {
+		case len(configProfile) > 0 && strings.HasPrefix(key, profilePrefix):
+			found[strings.TrimPrefix(key, profilePrefix)] = true
+		case !strings.HasPrefix(key, profilesKey+"."):
+			found[key] = true
+		}
+	}
+	keys := []string{}
+	for key := range found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commandFlagNames returns the names of the flags of the command and its subcommands.
+func commandFlagNames(cmd *cobra.Command) map[string]bool {
+	names := map[string]bool{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		names[f.Name] = true
+	})
+	for _, c := range cmd.Commands() {
+		for name := range commandFlagNames(c) {
+			names[name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testConfig = `dest-kube-context: dest-admin
+source-kube-context: source-admin
+source-copy-method: Snapshot
+profiles:
+  mysql:
+    source-namespace: db
+    source-pvc: mysql-pv-claim
+  uploads:
+    source-namespace: web
+    source-copy-method: Clone
+`
+
+func readTestConfig(t *testing.T, config string) *viper.Viper {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// setProfile selects the profile as --profile does until the end of the test.
+func setProfile(t *testing.T, profile string) {
+	previous := configProfile
+	configProfile = profile
+	t.Cleanup(func() { configProfile = previous })
+}
+
+func TestConfigValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		profile    string
+		key        string
+		wantValue  interface{}
+		wantSource string
+		wantFound  bool
+	}{
+		{
+			name:       "top level",
+			key:        "source-copy-method",
+			wantValue:  "Snapshot",
+			wantSource: "top level",
+			wantFound:  true,
+		},
+		{
+			name: "key of a profile without --profile",
+			key:  "source-namespace",
+		},
+		{
+			name: "unset key",
+			key:  "dest-namespace",
+		},
+		{
+			name: "profiles key",
+			key:  "profiles",
+		},
+		{
+			name:       "profile",
+			profile:    "mysql",
+			key:        "source-pvc",
+			wantValue:  "mysql-pv-claim",
+			wantSource: "profile mysql",
+			wantFound:  true,
+		},
+		{
+			name:       "top level inherited by the profile",
+			profile:    "mysql",
+			key:        "source-copy-method",
+			wantValue:  "Snapshot",
+			wantSource: "top level",
+			wantFound:  true,
+		},
+		{
+			name:       "profile overrides the top level",
+			profile:    "uploads",
+			key:        "source-copy-method",
+			wantValue:  "Clone",
+			wantSource: "profile uploads",
+			wantFound:  true,
+		},
+		{
+			name:    "key of another profile",
+			profile: "uploads",
+			key:     "source-pvc",
+		},
+	}
+	v := readTestConfig(t, testConfig)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setProfile(t, tt.profile)
+			value, source, found := configValue(v, tt.key)
+			if found != tt.wantFound || source != tt.wantSource || !reflect.DeepEqual(value, tt.wantValue) {
+				t.Errorf("expected %v from %q found %v, got %v from %q found %v", tt.wantValue, tt.wantSource, tt.wantFound, value, source, found)
+			}
+		})
+	}
+}
+
+func TestConfigKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    []string
+	}{
+		{
+			name: "top level",
+			want: []string{"dest-kube-context", "source-copy-method", "source-kube-context"},
+		},
+		{
+			name:    "profile",
+			profile: "mysql",
+			want:    []string{"dest-kube-context", "source-copy-method", "source-kube-context", "source-namespace", "source-pvc"},
+		},
+		{
+			name:    "profile overriding a top level key",
+			profile: "uploads",
+			want:    []string{"dest-kube-context", "source-copy-method", "source-kube-context", "source-namespace"},
+		},
+	}
+	v := readTestConfig(t, testConfig)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setProfile(t, tt.profile)
+			if got := configKeys(v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected keys %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
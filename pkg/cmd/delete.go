@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeDeleteLong = templates.LongDesc(`
+Delete a replication pair: the ReplicationSource, the ReplicationDestination and the SSH keys secret
+that sync-ssh-secret copied to the source namespace. With --delete-volumes, the PersistentVolumeClaims
+and VolumeSnapshots provisioned by the scribe operator for the pair are deleted as well. PersistentVolumeClaims
+passed with --dest-pvc or --source-pvc are never deleted.
+
+The objects to delete are listed and must be confirmed unless --yes is passed. With --dry-run, the objects
+are only listed.
+`)
+	scribeDeleteExample = templates.Examples(`
+        # List what would be deleted for the ReplicationDestination 'dest-destination' and its ReplicationSource.
+        scribe delete dest-destination --dest-namespace dest --source-namespace source --dry-run
+
+        # Delete the pair and the volumes and snapshots left by the scribe operator, without confirmation.
+        scribe teardown dest-destination --dest-namespace dest --source-namespace source --delete-volumes --yes
+    `)
+
+	// the scribe operator names destination snapshots scribe-dest-<name>-<YYYYMMDDHHMMSS>
+	destinationSnapshotSuffix = regexp.MustCompile(`^-[0-9]{14}$`)
+)
+
+type deleteOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	DeleteVolumes bool
+	DryRun        bool
+	Yes           bool
+	genericclioptions.IOStreams
+}
+
+// deleteItem is an object to delete in the source or destination cluster.
+type deleteItem struct {
+	side   string
+	client client.Client
+	obj    runtime.Object
+}
+
+func (i *deleteItem) String() string {
+	accessor, err := meta.Accessor(i.obj)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", i.side, i.obj)
+	}
+	kind := i.obj.GetObjectKind().GroupVersionKind().Kind
+	return fmt.Sprintf("%s: %s/%s in namespace %s", i.side, strings.ToLower(kind), accessor.GetName(), accessor.GetNamespace())
+}
+
+func NewDeleteOptions(streams genericclioptions.IOStreams) *deleteOptions {
+	return &deleteOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeDelete(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewDeleteOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "delete [NAME] [OPTIONS]",
+		Aliases: []string{"teardown"},
+		Short:   i18n.T("Delete a ReplicationDestination, its ReplicationSource and the artifacts created for them."),
+		Long:    fmt.Sprintf(scribeDeleteLong),
+		Example: fmt.Sprintf(scribeDeleteExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Delete())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *deleteOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVar(&o.DeleteVolumes, "delete-volumes", o.DeleteVolumes, "also delete the PersistentVolumeClaims and VolumeSnapshots provisioned by the scribe operator for the pair.")
+	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "if true, only list the objects that would be deleted.")
+	flags.BoolVarP(&o.Yes, "yes", "y", o.Yes, "delete without asking for confirmation.")
+}
+
+func (o *deleteOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *deleteOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// Delete deletes the replication pair after confirmation.
+func (o *deleteOptions) Delete() error {
+	ctx := context.Background()
+	items, err := o.itemsToDelete(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		fmt.Fprintln(o.Out, item)
+	}
+	if o.DryRun {
+		return nil
+	}
+	if !o.Yes {
+		fmt.Fprintf(o.Out, "Delete %d objects? [y/N]: ", len(items))
+		answer, err := bufio.NewReader(o.In).ReadString('\n')
+		if err != nil && len(answer) == 0 {
+			return err
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return fmt.Errorf("deletion cancelled")
+		}
+	}
+	for _, item := range items {
+		if err := item.client.Delete(ctx, item.obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+		klog.V(0).Infof("deleted %s", item)
+	}
+	return nil
+}
+
+// itemsToDelete returns the objects of the pair, ReplicationSource first so that it stops
+// connecting to the ReplicationDestination.
+func (o *deleteOptions) itemsToDelete(ctx context.Context) ([]*deleteItem, error) {
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return nil, err
+	}
+	sourceClient := o.scribeOptions.SourceClient
+	destClient := o.scribeOptions.DestinationClient
+	items := []*deleteItem{}
+	// only the secret copied by sync-ssh-secret is deleted, a secret of the user may be used by other pairs
+	sshKeys := destinationSSHKeysSecret(pair.Destination)
+	if pair.Source != nil {
+		pair.Source.SetGroupVersionKind(scribev1alpha1.GroupVersion.WithKind("ReplicationSource"))
+		items = append(items, &deleteItem{side: "source", client: sourceClient, obj: pair.Source})
+		if pair.Source.Spec.Rsync != nil && pair.Source.Spec.Rsync.SSHKeys != nil && *pair.Source.Spec.Rsync.SSHKeys != sshKeys {
+			klog.V(0).Infof("keeping secret %s of ReplicationSource %s, it was not copied by sync-ssh-secret", *pair.Source.Spec.Rsync.SSHKeys, pair.Source.Name)
+		}
+	}
+	secret := &corev1.Secret{}
+	err = sourceClient.Get(ctx, types.NamespacedName{Namespace: o.scribeOptions.sourceNamespace, Name: sshKeys}, secret)
+	switch {
+	case err == nil:
+		secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		items = append(items, &deleteItem{side: "source", client: sourceClient, obj: secret})
+	case !kerrors.IsNotFound(err):
+		return nil, err
+	}
+	pair.Destination.SetGroupVersionKind(scribev1alpha1.GroupVersion.WithKind("ReplicationDestination"))
+	items = append(items, &deleteItem{side: "destination", client: destClient, obj: pair.Destination})
+	if !o.DeleteVolumes {
+		return items, nil
+	}
+
+	if pair.Source != nil {
+		volumes, err := operatorVolumes(ctx, sourceClient, pair.Source.Namespace, "scribe-src-"+pair.Source.Name, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range volumes {
+			items = append(items, &deleteItem{side: "source", client: sourceClient, obj: obj})
+		}
+	}
+	volumes, err := operatorVolumes(ctx, destClient, pair.Destination.Namespace, "scribe-dest-"+pair.Destination.Name, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range volumes {
+		items = append(items, &deleteItem{side: "destination", client: destClient, obj: obj})
+	}
+	return items, nil
+}
+
+// operatorVolumes returns the PersistentVolumeClaim and VolumeSnapshots the scribe operator names
+// after baseName. Destination snapshots carry a timestamp suffix.
+func operatorVolumes(ctx context.Context, c client.Client, namespace, baseName string, timestamped bool) ([]runtime.Object, error) {
+	objs := []runtime.Object{}
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: baseName}, pvc)
+	switch {
+	case err == nil:
+		pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+		objs = append(objs, pvc)
+	case !kerrors.IsNotFound(err):
+		return nil, err
+	}
+
+	snapshots, err := listVolumeSnapshots(ctx, c, namespace)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// VolumeSnapshots are not installed in this cluster
+			return objs, nil
+		}
+		return nil, err
+	}
+	for i := range snapshots {
+		name := snapshots[i].GetName()
+		if name == baseName || (timestamped && strings.HasPrefix(name, baseName) && destinationSnapshotSuffix.MatchString(strings.TrimPrefix(name, baseName))) {
+			objs = append(objs, &snapshots[i])
+		}
+	}
+	return objs, nil
+}
@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/describe"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+	"github.com/operator-framework/operator-lib/status"
+)
+
+var (
+	scribeDescribeLong = templates.LongDesc(`
+Show a ReplicationDestination and the ReplicationSource that replicates to it in one report. The report
+includes the spec of both objects, the address and latest image published by the ReplicationDestination,
+their conditions, whether the SSH keys secret exists in each cluster, and the related events.
+
+The ReplicationSource is the one connecting to the address or using the SSH keys secret of the
+ReplicationDestination, unless --source-name is passed.
+`)
+	scribeDescribeExample = templates.Examples(`
+        # Describe the ReplicationDestination 'dest-destination' in namespace 'dest' and its ReplicationSource in namespace 'source'.
+        scribe describe dest-destination --dest-namespace dest --source-namespace source
+
+        # Describe a pair across clusters, naming the ReplicationSource explicitly.
+        scribe describe dest-destination --source-name source-source \
+            --dest-kube-context kind-kind --source-kube-context admin
+    `)
+)
+
+type describeOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	genericclioptions.IOStreams
+}
+
+func NewDescribeOptions(streams genericclioptions.IOStreams) *describeOptions {
+	return &describeOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeDescribe(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewDescribeOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "describe [NAME] [OPTIONS]",
+		Short:   i18n.T("Show a ReplicationDestination and its ReplicationSource end to end."),
+		Long:    fmt.Sprintf(scribeDescribeLong),
+		Example: fmt.Sprintf(scribeDescribeExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Describe())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *describeOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+}
+
+func (o *describeOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *describeOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// Describe prints the report for the replication pair.
+func (o *describeOptions) Describe() error {
+	ctx := context.Background()
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	out := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	w := describe.NewPrefixWriter(out)
+	if err := o.describeDestination(ctx, w, pair.Destination); err != nil {
+		return err
+	}
+	w.Write(describe.LEVEL_0, "\n")
+	if pair.Source == nil {
+		w.Write(describe.LEVEL_0, "ReplicationSource:\t<none>\n")
+	} else if err := o.describeSource(ctx, w, pair.Source); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func (o *describeOptions) describeDestination(ctx context.Context, w describe.PrefixWriter, rd *scribev1alpha1.ReplicationDestination) error {
+	w.Write(describe.LEVEL_0, "ReplicationDestination:\t%s\n", rd.Name)
+	w.Write(describe.LEVEL_1, "Context:\t%s\n", valueOrNone(&o.scribeOptions.destKubeContext))
+	w.Write(describe.LEVEL_1, "Namespace:\t%s\n", rd.Namespace)
+	w.Write(describe.LEVEL_1, "Created:\t%s\n", timeString(&rd.CreationTimestamp))
+	w.Write(describe.LEVEL_1, "Spec:\n")
+	var schedule *string
+	if rd.Spec.Trigger != nil {
+		schedule = rd.Spec.Trigger.Schedule
+	}
+	w.Write(describe.LEVEL_2, "Schedule:\t%s\n", valueOrNone(schedule))
+	w.Write(describe.LEVEL_2, "Paused:\t%t\n", rd.Spec.Paused)
+	if rd.Spec.Rsync != nil {
+		w.Write(describe.LEVEL_2, "Mover:\trsync\n")
+		opts := rd.Spec.Rsync.ReplicationDestinationVolumeOptions
+		describeVolumeOptions(w, opts.CopyMethod, opts.Capacity, opts.AccessModes, opts.StorageClassName, opts.VolumeSnapshotClassName)
+		w.Write(describe.LEVEL_2, "Destination PVC:\t%s\n", valueOrNone(opts.DestinationPVC))
+		describeRsyncSpec(w, rd.Spec.Rsync.SSHKeys, rd.Spec.Rsync.ServiceType, rd.Spec.Rsync.Address, rd.Spec.Rsync.Port, rd.Spec.Rsync.Path, rd.Spec.Rsync.SSHUser)
+	}
+	if rd.Spec.Rclone != nil {
+		w.Write(describe.LEVEL_2, "Mover:\trclone\n")
+		opts := rd.Spec.Rclone.ReplicationDestinationVolumeOptions
+		describeVolumeOptions(w, opts.CopyMethod, opts.Capacity, opts.AccessModes, opts.StorageClassName, opts.VolumeSnapshotClassName)
+		w.Write(describe.LEVEL_2, "Destination PVC:\t%s\n", valueOrNone(opts.DestinationPVC))
+		describeRcloneSpec(w, rd.Spec.Rclone.RcloneConfig, rd.Spec.Rclone.RcloneConfigSection, rd.Spec.Rclone.RcloneDestPath)
+	}
+	if rd.Spec.External != nil {
+		describeExternalSpec(w, rd.Spec.External.Provider, rd.Spec.External.Parameters)
+	}
+
+	w.Write(describe.LEVEL_1, "Status:\n")
+	if rd.Status == nil {
+		w.Write(describe.LEVEL_2, "<none>\n")
+	} else {
+		var address *string
+		if rd.Status.Rsync != nil {
+			address = rd.Status.Rsync.Address
+		}
+		w.Write(describe.LEVEL_2, "Address:\t%s\n", valueOrNone(address))
+		latestImage := "<none>"
+		if rd.Status.LatestImage != nil {
+			latestImage = rd.Status.LatestImage.Kind + "/" + rd.Status.LatestImage.Name
+		}
+		w.Write(describe.LEVEL_2, "Latest Image:\t%s\n", latestImage)
+		describeSyncTimes(w, rd.Status.LastSyncTime, rd.Status.LastSyncDuration, rd.Status.NextSyncTime)
+	}
+	var sshKeys *string
+	if rd.Status != nil && rd.Status.Rsync != nil {
+		sshKeys = rd.Status.Rsync.SSHKeys
+	}
+	// the destination uses the keys in spec.rsync.sshKeys when provided by the user
+	if rd.Spec.Rsync != nil && rd.Spec.Rsync.SSHKeys != nil {
+		sshKeys = rd.Spec.Rsync.SSHKeys
+	}
+	secret, err := secretStatus(ctx, o.scribeOptions.DestinationClient, rd.Namespace, sshKeys)
+	if err != nil {
+		return err
+	}
+	w.Write(describe.LEVEL_1, "SSH Keys Secret:\t%s\n", secret)
+	if rd.Status != nil {
+		describeConditions(w, rd.Status.Conditions)
+	}
+	return describeObjectEvents(ctx, w, o.scribeOptions.DestinationClient, rd.Namespace, "ReplicationDestination", rd.Name)
+}
+
+func (o *describeOptions) describeSource(ctx context.Context, w describe.PrefixWriter, rs *scribev1alpha1.ReplicationSource) error {
+	w.Write(describe.LEVEL_0, "ReplicationSource:\t%s\n", rs.Name)
+	w.Write(describe.LEVEL_1, "Context:\t%s\n", valueOrNone(&o.scribeOptions.sourceKubeContext))
+	w.Write(describe.LEVEL_1, "Namespace:\t%s\n", rs.Namespace)
+	w.Write(describe.LEVEL_1, "Created:\t%s\n", timeString(&rs.CreationTimestamp))
+	w.Write(describe.LEVEL_1, "Spec:\n")
+	w.Write(describe.LEVEL_2, "Source PVC:\t%s\n", rs.Spec.SourcePVC)
+	var schedule *string
+	if rs.Spec.Trigger != nil {
+		schedule = rs.Spec.Trigger.Schedule
+	}
+	w.Write(describe.LEVEL_2, "Schedule:\t%s\n", valueOrNone(schedule))
+	w.Write(describe.LEVEL_2, "Paused:\t%t\n", rs.Spec.Paused)
+	var sshKeys *string
+	if rs.Spec.Rsync != nil {
+		w.Write(describe.LEVEL_2, "Mover:\trsync\n")
+		opts := rs.Spec.Rsync.ReplicationSourceVolumeOptions
+		describeVolumeOptions(w, opts.CopyMethod, opts.Capacity, opts.AccessModes, opts.StorageClassName, opts.VolumeSnapshotClassName)
+		describeRsyncSpec(w, rs.Spec.Rsync.SSHKeys, rs.Spec.Rsync.ServiceType, rs.Spec.Rsync.Address, rs.Spec.Rsync.Port, rs.Spec.Rsync.Path, rs.Spec.Rsync.SSHUser)
+		sshKeys = rs.Spec.Rsync.SSHKeys
+	}
+	if rs.Spec.Rclone != nil {
+		w.Write(describe.LEVEL_2, "Mover:\trclone\n")
+		opts := rs.Spec.Rclone.ReplicationSourceVolumeOptions
+		describeVolumeOptions(w, opts.CopyMethod, opts.Capacity, opts.AccessModes, opts.StorageClassName, opts.VolumeSnapshotClassName)
+		describeRcloneSpec(w, rs.Spec.Rclone.RcloneConfig, rs.Spec.Rclone.RcloneConfigSection, rs.Spec.Rclone.RcloneDestPath)
+	}
+	if rs.Spec.External != nil {
+		describeExternalSpec(w, rs.Spec.External.Provider, rs.Spec.External.Parameters)
+	}
+
+	w.Write(describe.LEVEL_1, "Status:\n")
+	if rs.Status == nil {
+		w.Write(describe.LEVEL_2, "<none>\n")
+	} else {
+		describeSyncTimes(w, rs.Status.LastSyncTime, rs.Status.LastSyncDuration, rs.Status.NextSyncTime)
+	}
+	secret, err := secretStatus(ctx, o.scribeOptions.SourceClient, rs.Namespace, sshKeys)
+	if err != nil {
+		return err
+	}
+	w.Write(describe.LEVEL_1, "SSH Keys Secret:\t%s\n", secret)
+	if rs.Status != nil {
+		describeConditions(w, rs.Status.Conditions)
+	}
+	return describeObjectEvents(ctx, w, o.scribeOptions.SourceClient, rs.Namespace, "ReplicationSource", rs.Name)
+}
+
+func describeVolumeOptions(w describe.PrefixWriter, copyMethod scribev1alpha1.CopyMethodType, capacity *resource.Quantity, accessModes []corev1.PersistentVolumeAccessMode, storageClassName, volumeSnapshotClassName *string) {
+	w.Write(describe.LEVEL_2, "Copy Method:\t%s\n", copyMethod)
+	capacityString := "<none>"
+	if capacity != nil {
+		capacityString = capacity.String()
+	}
+	w.Write(describe.LEVEL_2, "Capacity:\t%s\n", capacityString)
+	modes := []string{}
+	for _, mode := range accessModes {
+		modes = append(modes, string(mode))
+	}
+	accessModesString := strings.Join(modes, ",")
+	w.Write(describe.LEVEL_2, "Access Modes:\t%s\n", valueOrNone(&accessModesString))
+	w.Write(describe.LEVEL_2, "Storage Class:\t%s\n", valueOrNone(storageClassName))
+	w.Write(describe.LEVEL_2, "Volume Snapshot Class:\t%s\n", valueOrNone(volumeSnapshotClassName))
+}
+
+func describeRsyncSpec(w describe.PrefixWriter, sshKeys *string, serviceType *corev1.ServiceType, address *string, port *int32, path, sshUser *string) {
+	w.Write(describe.LEVEL_2, "SSH Keys:\t%s\n", valueOrNone(sshKeys))
+	serviceTypeString := ""
+	if serviceType != nil {
+		serviceTypeString = string(*serviceType)
+	}
+	w.Write(describe.LEVEL_2, "Service Type:\t%s\n", valueOrNone(&serviceTypeString))
+	w.Write(describe.LEVEL_2, "Address:\t%s\n", valueOrNone(address))
+	portString := ""
+	if port != nil {
+		portString = fmt.Sprintf("%d", *port)
+	}
+	w.Write(describe.LEVEL_2, "Port:\t%s\n", valueOrNone(&portString))
+	w.Write(describe.LEVEL_2, "Path:\t%s\n", valueOrNone(path))
+	w.Write(describe.LEVEL_2, "SSH User:\t%s\n", valueOrNone(sshUser))
+}
+
+func describeRcloneSpec(w describe.PrefixWriter, rcloneConfig, rcloneConfigSection, rcloneDestPath *string) {
+	w.Write(describe.LEVEL_2, "Rclone Config:\t%s\n", valueOrNone(rcloneConfig))
+	w.Write(describe.LEVEL_2, "Rclone Config Section:\t%s\n", valueOrNone(rcloneConfigSection))
+	w.Write(describe.LEVEL_2, "Rclone Destination Path:\t%s\n", valueOrNone(rcloneDestPath))
+}
+
+func describeExternalSpec(w describe.PrefixWriter, provider string, parameters map[string]string) {
+	w.Write(describe.LEVEL_2, "Provider:\t%s\n", valueOrNone(&provider))
+	w.Write(describe.LEVEL_2, "Provider Parameters:\n")
+	keys := []string{}
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.Write(describe.LEVEL_3, "%s:\t%s\n", k, parameters[k])
+	}
+}
+
+func describeSyncTimes(w describe.PrefixWriter, lastSyncTime *metav1.Time, lastSyncDuration *metav1.Duration, nextSyncTime *metav1.Time) {
+	w.Write(describe.LEVEL_2, "Last Sync Time:\t%s\n", timeString(lastSyncTime))
+	durationString := ""
+	if lastSyncDuration != nil {
+		durationString = lastSyncDuration.Duration.String()
+	}
+	w.Write(describe.LEVEL_2, "Last Sync Duration:\t%s\n", valueOrNone(&durationString))
+	w.Write(describe.LEVEL_2, "Next Sync Time:\t%s\n", timeString(nextSyncTime))
+}
+
+func describeConditions(w describe.PrefixWriter, conditions status.Conditions) {
+	if len(conditions) == 0 {
+		w.Write(describe.LEVEL_1, "Conditions:\t<none>\n")
+		return
+	}
+	w.Write(describe.LEVEL_1, "Conditions:\n")
+	w.Write(describe.LEVEL_2, "Type\tStatus\tReason\tLast Transition\tMessage\n")
+	w.Write(describe.LEVEL_2, "----\t------\t------\t---------------\t-------\n")
+	for _, c := range conditions {
+		w.Write(describe.LEVEL_2, "%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, translateTimestampSince(&c.LastTransitionTime), c.Message)
+	}
+}
+
+// describeObjectEvents writes the events of the named object in the namespace.
+func describeObjectEvents(ctx context.Context, w describe.PrefixWriter, c client.Client, namespace, kind, name string) error {
+	events := &corev1.EventList{}
+	err := c.List(ctx, events,
+		client.InNamespace(namespace),
+		client.MatchingFields{"involvedObject.kind": kind, "involvedObject.name": name},
+	)
+	if err != nil {
+		return err
+	}
+	describe.DescribeEvents(events, w)
+	return nil
+}
+
+// secretStatus returns the name of the secret and whether it exists in the namespace.
+func secretStatus(ctx context.Context, c client.Client, namespace string, name *string) (string, error) {
+	if name == nil || len(*name) == 0 {
+		return "<none>", nil
+	}
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: *name}, secret)
+	switch {
+	case kerrors.IsNotFound(err):
+		return *name + " (not found)", nil
+	case err != nil:
+		return "", err
+	}
+	return *name + " (found)", nil
+}
+
+// timeString returns the timestamp with the time elapsed since.
+func timeString(timestamp *metav1.Time) string {
+	if timestamp == nil || timestamp.IsZero() {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s (%s ago)", timestamp.Format(time.RFC3339), translateTimestampSince(timestamp))
+}
@@ -3,19 +3,17 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
-	"k8s.io/klog/v2"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 )
@@ -35,12 +33,22 @@ Scribe is a command line tool for a scribe operator running in a Kubernetes clus
         scribe new-destination --dest-namespace dest \
 		    --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce \
 			--dest-kube-context scribe-user --dest-kube-clustername api-test-test-com:6443
+
+        # Create a ReplicationDestination in the namespace 'dest' that syncs from the path 'scribe-mysql' of the rclone
+        # remote 'aws-s3-bucket', defined in the rclone.conf of the secret 'rclone-secret'.
+        scribe new-destination --dest-namespace dest --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce \
+            --mover rclone --rclone-config rclone-secret --rclone-config-section aws-s3-bucket --rclone-dest-path scribe-mysql
+
+        # Print the ReplicationDestination as YAML without creating it, to commit it to a GitOps repository.
+        scribe new-destination --dest-namespace dest --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce \
+            --dry-run=client -o yaml
     `)
 )
 
 type destinationOptions struct {
 	scribeOptions               scribeOptions
 	sshKeysSecretOptions        sshKeysSecretOptions
+	printOptions                printOptions
 	DestCopyMethod              string //v1alpha1.CopyMethodType
 	DestCapacity                string //*resource.Quantity
 	DestStorageClassName        string
@@ -55,7 +63,10 @@ type destinationOptions struct {
 	DestServiceType             string //*corev1.ServiceType
 	Port                        int32  //int32
 	Path                        string
+	Mover                       string
 	RcloneConfig                string
+	RcloneConfigSection         string
+	RcloneDestPath              string
 	Provider                    string
 	ProviderParameters          string //map[string]string
 	genericclioptions.IOStreams
@@ -63,7 +74,8 @@ type destinationOptions struct {
 
 func NewDestinationOptions(streams genericclioptions.IOStreams) *destinationOptions {
 	return &destinationOptions{
-		IOStreams: streams,
+		printOptions: newPrintOptions("created"),
+		IOStreams:    streams,
 	}
 }
 
@@ -88,10 +100,19 @@ func NewCmdScribeNewDestination(streams genericclioptions.IOStreams) *cobra.Comm
 	return cmd
 }
 
-func (o *destinationOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) error {
+func (o *destinationOptions) bindFlags(cmd *cobra.Command) {
+	o.bindDestinationFlags(cmd)
+	o.printOptions.bindFlags(cmd)
+	o.bindRemoteFlags(cmd)
+	o.bindMoverFlags(cmd)
+	cmd.MarkFlagRequired("dest-copy-method")
+}
+
+// bindDestinationFlags binds the flags that only apply to the ReplicationDestination, so that
+// commands also creating a ReplicationSource can bind them next to the source flags.
+func (o *destinationOptions) bindDestinationFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.StringVar(&o.DestCopyMethod, "dest-copy-method", o.DestCopyMethod, "the method of creating a point-in-time image of the destination volume; one of 'None|Clone|Snapshot'")
-	flags.StringVar(&o.Address, "address", o.Address, "the remote address to connect to for replication.")
 	// TODO: Defaulted with CLI, should it be??
 	flags.StringVar(&o.DestCapacity, "dest-capacity", "2Gi", "Size of the destination volume to create. Must be provided if --dest-pvc is not provided.")
 	flags.StringVar(&o.DestStorageClassName, "dest-storage-class-name", o.DestStorageClassName, "name of the StorageClass of the destination volume. If not set, the default StorageClass will be used.")
@@ -105,34 +126,33 @@ func (o *destinationOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) error
 	flags.StringVar(&o.DestServiceType, "dest-service-type", o.DestServiceType, "one of ClusterIP|LoadBalancer. Service type to be created for incoming SSH connections. (default 'ClusterIP')")
 	// TODO: Defaulted in CLI, should it be??
 	flags.StringVar(&o.DestName, "dest-name", o.DestName, "name of the ReplicationDestination resource. (default '<current-namespace>-scribe-destination')")
+}
+
+// bindRemoteFlags binds the flags describing the rsync connection to the remote side and the external provider.
+func (o *destinationOptions) bindRemoteFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.Address, "address", o.Address, "the remote address to connect to for replication.")
 	flags.Int32Var(&o.Port, "port", o.Port, "SSH port to connect to for replication. (default 22)")
 	flags.StringVar(&o.Provider, "provider", o.Provider, "name of an external replication provider, if applicable; pass as 'domain.com/provider'")
 	// TODO: I don't know how many params providers have? If a lot, can pass a file instead
 	flags.StringVar(&o.ProviderParameters, "provider-parameters", o.ProviderParameters, "provider-specific key/value configuration parameters, if using an external provider; pass as 'key/value,key1/value1,key2/value2'")
 	// defaults to "/" after creation
 	flags.StringVar(&o.Path, "path", o.Path, "the remote path to rsync to (default '/')")
-	cmd.MarkFlagRequired("dest-copy-method")
-	flags.VisitAll(func(f *pflag.Flag) {
-		if !f.Changed && v.IsSet(f.Name) {
-			val := v.Get(f.Name)
-			flags.Set(f.Name, fmt.Sprintf("%v", val))
-		}
-	})
-	return nil
+}
+
+// bindMoverFlags binds the flags selecting and configuring the data mover.
+func (o *destinationOptions) bindMoverFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.Mover, "mover", "rsync", "the data mover used for replication; one of 'rsync|rclone'")
+	flags.StringVar(&o.RcloneConfig, "rclone-config", o.RcloneConfig, "name of the secret holding the rclone.conf, in the namespace of the ReplicationDestination. Required with --mover rclone.")
+	flags.StringVar(&o.RcloneConfigSection, "rclone-config-section", o.RcloneConfigSection, "the section of the rclone.conf defining the remote to sync from. Required with --mover rclone.")
+	flags.StringVar(&o.RcloneDestPath, "rclone-dest-path", o.RcloneDestPath, "the path on the remote to sync from, such as a bucket name. Required with --mover rclone.")
 }
 
 func (o *destinationOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
-	// config file in current directory
-	// TODO: where to look for config file
-	v.SetConfigName(scribeConfig)
-	v.AddConfigPath(".")
-	v.SetConfigType("yaml")
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
-		}
-	}
-	o.bindFlags(cmd, v)
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
 	return nil
 }
 
@@ -141,6 +161,9 @@ func (o *destinationOptions) Complete(cmd *cobra.Command) error {
 	if err != nil {
 		return err
 	}
+	if err := o.printOptions.Complete(cmd); err != nil {
+		return err
+	}
 	o.DestNamespace = o.scribeOptions.destNamespace
 	if len(o.DestName) == 0 {
 		o.DestName = o.DestNamespace + "-destination"
@@ -149,22 +172,89 @@ func (o *destinationOptions) Complete(cmd *cobra.Command) error {
 	return nil
 }
 
-// Validate validates ReplicationDestination options.
+// Validate validates ReplicationDestination options, then checks them against the destination cluster
+// unless the ReplicationDestination is only printed with --dry-run=client.
 func (o *destinationOptions) Validate() error {
+	if err := o.validateFlags(); err != nil {
+		return err
+	}
+	if o.printOptions.DryRunStrategy == kcmdutil.DryRunClient {
+		return nil
+	}
+	_, err := o.preflight(context.TODO())
+	return err
+}
+
+// validateFlags validates ReplicationDestination options without reading the cluster.
+func (o *destinationOptions) validateFlags() error {
 	if len(o.DestCopyMethod) == 0 {
 		return fmt.Errorf("must provide --copy-method; one of 'None|Clone|Snapshot'")
 	}
+	if err := validateMover(o.Mover, o.RcloneConfig, o.RcloneConfigSection, o.RcloneDestPath); err != nil {
+		return err
+	}
 	if len(o.DestCapacity) == 0 && len(o.DestPVC) == 0 {
 		return fmt.Errorf("must either provide --dest-capacity & --dest-access-mode OR --dest-pvc")
 	}
 	if len(o.DestAccessMode) == 0 && len(o.DestPVC) == 0 {
 		return fmt.Errorf("must either provide --dest-capacity & --dest-access-mode OR --dest-pvc")
 	}
-	return nil
+	if err := validateCapacity(o.DestCapacity, "--dest-capacity"); err != nil {
+		return err
+	}
+	return validateSchedule(o.DestSchedule, "--dest-cron-spec")
+}
+
+// preflight checks that the destination cluster can run the ReplicationDestination: the CRD is installed,
+// the namespace, --dest-pvc, the StorageClass and the VolumeSnapshotClass exist. It returns the
+// PersistentVolumeClaim passed with --dest-pvc, if any.
+func (o *destinationOptions) preflight(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	c := o.scribeOptions.DestinationClient
+	kubeContext := o.scribeOptions.destKubeContext
+	if err := preflightNamespace(ctx, c, o.DestNamespace, kubeContext); err != nil {
+		return nil, err
+	}
+	if err := preflightCRD(ctx, c, &scribev1alpha1.ReplicationDestinationList{}, "ReplicationDestination", o.DestNamespace, kubeContext); err != nil {
+		return nil, err
+	}
+	var pvc *corev1.PersistentVolumeClaim
+	if len(o.DestPVC) > 0 {
+		var err error
+		if pvc, err = preflightPVC(ctx, c, o.DestNamespace, o.DestPVC, "--dest-pvc", kubeContext); err != nil {
+			return nil, err
+		}
+	}
+	if err := preflightStorageClass(ctx, c, o.DestStorageClassName, "--dest-storage-class-name", kubeContext); err != nil {
+		return nil, err
+	}
+	if copyMethod, _ := parseCopyMethod(o.DestCopyMethod); copyMethod == scribev1alpha1.CopyMethodSnapshot || len(o.DestVolumeSnapshotClassName) > 0 {
+		if err := preflightSnapshotClass(ctx, c, o.DestVolumeSnapshotClassName, "--dest-volume-snapshot-class", kubeContext); err != nil {
+			return nil, err
+		}
+	}
+	return pvc, nil
 }
 
 // CreateReplicationDestination creates a ReplicationDestination resource
 func (o *destinationOptions) CreateReplicationDestination() error {
+	obj, err := o.newReplicationDestination()
+	if err != nil {
+		return err
+	}
+	return o.printOptions.create(context.TODO(), o.scribeOptions.DestinationClient, obj, o.Out)
+}
+
+// defaultedFields returns the spec fields newReplicationDestination sets to a default because the options
+// do not set them.
+func (o *destinationOptions) defaultedFields() [][]string {
+	if o.Mover != "rclone" && len(o.DestServiceType) == 0 {
+		return [][]string{{"spec", "rsync", "serviceType"}}
+	}
+	return nil
+}
+
+// newReplicationDestination returns the ReplicationDestination described by the options.
+func (o *destinationOptions) newReplicationDestination() (*scribev1alpha1.ReplicationDestination, error) {
 	c := &commonOptions{}
 	switch {
 	case len(o.DestCapacity) > 0:
@@ -173,41 +263,28 @@ func (o *destinationOptions) CreateReplicationDestination() error {
 	default:
 		c.capacity = nil
 	}
-	if o.Port == 0 {
-		c.port = nil
+	if o.Port != 0 {
+		c.port = &o.Port
 	}
-	switch o.DestCopyMethod {
-	case "None", "none":
-		c.copyMethod = scribev1alpha1.CopyMethodNone
-	case "Clone", "clone":
-		c.copyMethod = scribev1alpha1.CopyMethodClone
-	case "Snapshot", "snapshot", "SnapShot":
-		c.copyMethod = scribev1alpha1.CopyMethodSnapshot
-	default:
-		return fmt.Errorf("unrecognized --dest-copy-method: %s", o.DestCopyMethod)
+	copyMethod, err := parseCopyMethod(o.DestCopyMethod)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized --dest-copy-method: %s", o.DestCopyMethod)
 	}
+	c.copyMethod = copyMethod
 	if len(o.DestAccessMode) > 0 {
-		switch o.DestAccessMode {
-		case "ReadWriteOnce":
-			c.accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
-		case "ReadWriteMany":
-			c.accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
-		case "ReadOnlyMany":
-			c.accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}
-		default:
-			return fmt.Errorf("unrecognized --dest-access-mode %s", o.DestAccessMode)
+		accessModes, err := parseAccessMode(o.DestAccessMode)
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized --dest-access-mode %s", o.DestAccessMode)
 		}
+		c.accessModes = accessModes
 	}
 	switch {
 	case len(o.DestServiceType) > 0:
-		switch o.DestServiceType {
-		case "ClusterIP", "clusterip", "clusterIP":
-			c.serviceType = corev1.ServiceTypeClusterIP
-		case "LoadBalancer", "loadbalancer", "Loadbalancer":
-			c.serviceType = corev1.ServiceTypeLoadBalancer
-		default:
-			return fmt.Errorf("unrecognized --dest-service-type %s", o.DestServiceType)
+		serviceType, err := parseServiceType(o.DestServiceType)
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized --dest-service-type %s", o.DestServiceType)
 		}
+		c.serviceType = serviceType
 	// if not set, then default to clusterIP
 	default:
 		c.serviceType = corev1.ServiceTypeClusterIP
@@ -237,16 +314,8 @@ func (o *destinationOptions) CreateReplicationDestination() error {
 	if len(o.DestPVC) > 0 {
 		c.pvc = &o.DestPVC
 	}
-	c.parameters = make(map[string]string)
-	if len(o.ProviderParameters) > 0 {
-		p := strings.Split(o.ProviderParameters, ",")
-		for _, kv := range p {
-			pair := strings.Split(kv, "/")
-			if len(pair) != 2 {
-				return fmt.Errorf("error parsing --provider-parameters %s, must be passed as key/value,key1/value1...", o.ProviderParameters)
-			}
-			c.parameters[pair[0]] = pair[1]
-		}
+	if c.parameters, err = parseProviderParameters(o.ProviderParameters); err != nil {
+		return nil, err
 	}
 	triggerSpec := &scribev1alpha1.ReplicationDestinationTriggerSpec{
 		Schedule: &o.DestSchedule,
@@ -254,21 +323,34 @@ func (o *destinationOptions) CreateReplicationDestination() error {
 	if len(o.DestSchedule) == 0 {
 		triggerSpec = nil
 	}
-	rsyncSpec := &scribev1alpha1.ReplicationDestinationRsyncSpec{
-		ReplicationDestinationVolumeOptions: scribev1alpha1.ReplicationDestinationVolumeOptions{
-			CopyMethod:              c.copyMethod,
-			Capacity:                c.capacity,
-			StorageClassName:        c.storageClassName,
-			AccessModes:             c.accessModes,
-			VolumeSnapshotClassName: c.volumeSnapClassName,
-			DestinationPVC:          c.pvc,
-		},
-		SSHKeys:     c.sshKeysSecret,
-		SSHUser:     c.sshUser,
-		Address:     c.address,
-		ServiceType: &c.serviceType,
-		Port:        c.port,
-		Path:        c.path,
+	volumeOptions := scribev1alpha1.ReplicationDestinationVolumeOptions{
+		CopyMethod:              c.copyMethod,
+		Capacity:                c.capacity,
+		StorageClassName:        c.storageClassName,
+		AccessModes:             c.accessModes,
+		VolumeSnapshotClassName: c.volumeSnapClassName,
+		DestinationPVC:          c.pvc,
+	}
+	var rsyncSpec *scribev1alpha1.ReplicationDestinationRsyncSpec
+	var rcloneSpec *scribev1alpha1.ReplicationDestinationRcloneSpec
+	switch o.Mover {
+	case "rclone":
+		rcloneSpec = &scribev1alpha1.ReplicationDestinationRcloneSpec{
+			ReplicationDestinationVolumeOptions: volumeOptions,
+			RcloneConfig:                        &o.RcloneConfig,
+			RcloneConfigSection:                 &o.RcloneConfigSection,
+			RcloneDestPath:                      &o.RcloneDestPath,
+		}
+	default:
+		rsyncSpec = &scribev1alpha1.ReplicationDestinationRsyncSpec{
+			ReplicationDestinationVolumeOptions: volumeOptions,
+			SSHKeys:                             c.sshKeysSecret,
+			SSHUser:                             c.sshUser,
+			Address:                             c.address,
+			ServiceType:                         &c.serviceType,
+			Port:                                c.port,
+			Path:                                c.path,
+		}
 	}
 	var externalSpec *scribev1alpha1.ReplicationDestinationExternalSpec
 	if len(o.Provider) > 0 && c.parameters != nil {
@@ -289,12 +371,9 @@ func (o *destinationOptions) CreateReplicationDestination() error {
 		Spec: scribev1alpha1.ReplicationDestinationSpec{
 			Trigger:  triggerSpec,
 			Rsync:    rsyncSpec,
+			Rclone:   rcloneSpec,
 			External: externalSpec,
 		},
 	}
-	if err := o.scribeOptions.DestinationClient.Create(context.TODO(), rd); err != nil {
-		return err
-	}
-	klog.V(0).Infof("ReplicationDestination %s created in namespace %s", o.DestName, o.DestNamespace)
-	return nil
+	return rd, nil
 }
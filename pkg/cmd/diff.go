@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeDiffLong = templates.LongDesc(`
+Compare the ReplicationDestination and ReplicationSource described by a ReplicationPlan file, or by the
+flags and scribe-config as for replicate, with the live objects of the destination and source clusters.
+The objects are built as new-destination and new-source would create them, and a unified diff is printed
+for each object that drifted or does not exist.
+
+Only the fields set in the plan or by the flags are compared. The address and SSH keys secret a
+ReplicationSource gets from its ReplicationDestination are not compared unless they are set. With -f,
+the flags describing the pair are ignored and the kube contexts and namespaces of the flags are only
+used when the plan does not set them.
+
+The exit status is 0 when the live objects match, 1 when they drifted and greater than 1 on error.
+`)
+	scribeDiffExample = templates.Examples(`
+        # Compare the pairs of a plan with the clusters.
+        scribe diff -f plan.yaml
+
+        # Compare the pair described by the scribe config with the clusters.
+        scribe diff
+
+        # Compare the pair described by flags, as passed to replicate.
+        scribe diff --source-namespace source --source-pvc mysql-pvc --source-copy-method Snapshot \
+            --dest-namespace dest --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce
+    `)
+)
+
+type diffOptions struct {
+	scribeOptions      scribeOptions
+	destinationOptions destinationOptions
+	sourceOptions      sourceOptions
+	Filename           string
+	genericclioptions.IOStreams
+}
+
+func NewDiffOptions(streams genericclioptions.IOStreams) *diffOptions {
+	return &diffOptions{
+		destinationOptions: destinationOptions{IOStreams: streams},
+		sourceOptions:      sourceOptions{IOStreams: streams},
+		IOStreams:          streams,
+	}
+}
+
+func NewCmdScribeDiff(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewDiffOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "diff [-f FILENAME] [OPTIONS]",
+		Short:   i18n.T("Show the differences between a ReplicationPlan or scribe-config and the live replication objects."),
+		Long:    fmt.Sprintf(scribeDiffLong),
+		Example: fmt.Sprintf(scribeDiffExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckDiffErr(o.Complete(cmd))
+			kcmdutil.CheckDiffErr(o.Validate())
+			drift, err := o.Diff()
+			kcmdutil.CheckDiffErr(err)
+			if drift {
+				os.Exit(1)
+			}
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *diffOptions) bindFlags(cmd *cobra.Command) {
+	o.destinationOptions.bindDestinationFlags(cmd)
+	o.sourceOptions.bindSourceFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Filename, "filename", "f", o.Filename, "the ReplicationPlan file to compare, '-' to read it from stdin. If not set, compare the pair described by the flags.")
+	// defaults to 22 after creation
+	flags.Int32Var(&o.sourceOptions.Port, "port", o.sourceOptions.Port, "SSH port of the ReplicationDestination for replication. (default 22)")
+	// defaults to "/" after creation
+	flags.StringVar(&o.sourceOptions.Path, "path", o.sourceOptions.Path, "the remote path to rsync to (default '/')")
+	o.sourceOptions.bindMoverFlags(cmd)
+}
+
+func (o *diffOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+// Complete builds the clients of the pair described by the flags. The clients of the pairs of a plan are
+// built when they are compared.
+func (o *diffOptions) Complete(cmd *cobra.Command) error {
+	if len(o.Filename) > 0 {
+		return nil
+	}
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return completePair(cmd, o.scribeOptions, &o.destinationOptions, &o.sourceOptions)
+}
+
+// Validate validates the pair described by the flags. The preflight checks of replicate are not run, as
+// diff reports the state of the clusters rather than failing on it. A plan is validated when it is loaded.
+func (o *diffOptions) Validate() error {
+	if len(o.Filename) > 0 {
+		return nil
+	}
+	return validatePairFlags(&o.destinationOptions, &o.sourceOptions)
+}
+
+// Diff prints the differences between the planned and the live objects and returns true if there are any.
+func (o *diffOptions) Diff() (bool, error) {
+	ctx := context.Background()
+	if len(o.Filename) == 0 {
+		return o.diffPair(ctx, &o.destinationOptions, &o.sourceOptions)
+	}
+	plan, err := loadReplicationPlan(o.Filename)
+	if err != nil {
+		return false, err
+	}
+	drift := false
+	for i := range plan.Pairs {
+		pair := &plan.Pairs[i]
+		so := pair.scribeOptions(o.scribeOptions)
+		if err := so.Complete(); err != nil {
+			return drift, fmt.Errorf("pair %s: %v", pair.Name, err)
+		}
+		pairDrift, err := o.diffPair(ctx, pair.destinationOptions(so, o.IOStreams), pair.sourceOptions(so, o.IOStreams))
+		if err != nil {
+			return drift, fmt.Errorf("pair %s: %v", pair.Name, err)
+		}
+		drift = drift || pairDrift
+	}
+	return drift, nil
+}
+
+// diffPair prints the differences of the ReplicationDestination and the ReplicationSource of a pair.
+func (o *diffOptions) diffPair(ctx context.Context, d *destinationOptions, s *sourceOptions) (bool, error) {
+	rd, err := d.newReplicationDestination()
+	if err != nil {
+		return false, err
+	}
+	rs, err := s.newReplicationSource()
+	if err != nil {
+		return false, err
+	}
+	objects := []struct {
+		client                client.Client
+		planned, live, merged runtime.Object
+		defaulted             [][]string
+	}{
+		{d.scribeOptions.DestinationClient, rd, &scribev1alpha1.ReplicationDestination{}, &scribev1alpha1.ReplicationDestination{}, d.defaultedFields()},
+		{s.scribeOptions.SourceClient, rs, &scribev1alpha1.ReplicationSource{}, &scribev1alpha1.ReplicationSource{}, s.defaultedFields()},
+	}
+	drift := false
+	for _, obj := range objects {
+		diff, err := diffPlanned(ctx, obj.client, obj.planned, obj.live, obj.merged, obj.defaulted)
+		if err != nil {
+			return drift, err
+		}
+		if len(diff) > 0 {
+			drift = true
+			fmt.Fprint(o.Out, diff)
+		}
+	}
+	return drift, nil
+}
+
+// diffPlanned returns the diff between the live object and the live object with the fields of the plan,
+// or the whole planned object if it does not exist. live and merged are empty objects of the planned type.
+// The defaulted fields are only compared when the object does not exist.
+func diffPlanned(ctx context.Context, c client.Client, planned, live, merged runtime.Object, defaulted [][]string) (string, error) {
+	nsName, name, err := plannedName(planned)
+	if err != nil {
+		return "", err
+	}
+	err = c.Get(ctx, nsName, live)
+	if kerrors.IsNotFound(err) {
+		return objectDiff(name, "live", "plan", nil, planned)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := mergePlanned(live, planned, merged, defaulted); err != nil {
+		return "", err
+	}
+	return objectDiff(name, "live", "plan", live, merged)
+}
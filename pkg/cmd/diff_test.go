@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+func TestDiffPlanned(t *testing.T) {
+	tests := []struct {
+		name string
+		// live changes the live object, which starts as the planned object; nil live objects do not exist.
+		live     func(rd *scribev1alpha1.ReplicationDestination) *scribev1alpha1.ReplicationDestination
+		plan     func(plan string) string
+		wantDiff []string
+	}{
+		{
+			name: "live object equals plan",
+			live: func(rd *scribev1alpha1.ReplicationDestination) *scribev1alpha1.ReplicationDestination { return rd },
+		},
+		{
+			name: "service type not set in the plan",
+			live: func(rd *scribev1alpha1.ReplicationDestination) *scribev1alpha1.ReplicationDestination {
+				serviceType := corev1.ServiceTypeLoadBalancer
+				rd.Spec.Rsync.ServiceType = &serviceType
+				return rd
+			},
+		},
+		{
+			name: "service type set in the plan",
+			live: func(rd *scribev1alpha1.ReplicationDestination) *scribev1alpha1.ReplicationDestination {
+				serviceType := corev1.ServiceTypeLoadBalancer
+				rd.Spec.Rsync.ServiceType = &serviceType
+				return rd
+			},
+			plan: func(plan string) string {
+				return plan + "    serviceType: ClusterIP\n"
+			},
+			wantDiff: []string{"-    serviceType: LoadBalancer", "+    serviceType: ClusterIP"},
+		},
+		{
+			name: "drifted capacity",
+			live: func(rd *scribev1alpha1.ReplicationDestination) *scribev1alpha1.ReplicationDestination {
+				capacity := resource.MustParse("1Gi")
+				rd.Spec.Rsync.Capacity = &capacity
+				return rd
+			},
+			wantDiff: []string{"-    capacity: 1Gi", "+    capacity: 2Gi"},
+		},
+		{
+			name:     "missing object",
+			live:     func(rd *scribev1alpha1.ReplicationDestination) *scribev1alpha1.ReplicationDestination { return nil },
+			wantDiff: []string{"+    serviceType: ClusterIP", "+    capacity: 2Gi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live, _ := plannedDestination(t, testPlan)
+			c := fake.NewFakeClientWithScheme(scheme)
+			if live = tt.live(live); live != nil {
+				if err := c.Create(context.Background(), live); err != nil {
+					t.Fatal(err)
+				}
+			}
+			plan := testPlan
+			if tt.plan != nil {
+				plan = tt.plan(plan)
+			}
+			planned, defaulted := plannedDestination(t, plan)
+			diff, err := diffPlanned(context.Background(), c, planned, &scribev1alpha1.ReplicationDestination{}, &scribev1alpha1.ReplicationDestination{}, defaulted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.wantDiff) == 0 && len(diff) > 0 {
+				t.Errorf("expected no drift, got:\n%s", diff)
+			}
+			for _, line := range tt.wantDiff {
+				if !strings.Contains(diff, line) {
+					t.Errorf("expected %q in the diff, got:\n%s", line, diff)
+				}
+			}
+		})
+	}
+}
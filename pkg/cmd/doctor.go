@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeDoctorLong = templates.LongDesc(`
+Diagnose a replication pair that does not sync. Doctor runs a catalog of checks against the source and
+destination clusters and reports each as pass, warn or fail, with a hint to fix what fails:
+
+* a ReplicationSource replicates to the ReplicationDestination, and neither is paused
+* the conditions of the ReplicationSource and the ReplicationDestination are met
+* the mover Jobs and their Pods are not failing, crashing or unschedulable
+* the rsync Service of the ReplicationDestination has a LoadBalancer address, and the ReplicationSource
+  connects to the address the ReplicationDestination publishes
+* the SSH keys secret of the ReplicationSource is the same as the one of the ReplicationDestination
+* the volumes of both sides are Bound
+* the VolumeSnapshots of both sides are ReadyToUse
+
+The pair is selected as for status. The command exits with a non-zero status when a check fails.
+`)
+	scribeDoctorExample = templates.Examples(`
+        # Diagnose the ReplicationDestination 'dest-destination' and its ReplicationSource.
+        scribe doctor dest-destination --dest-namespace dest --source-namespace source
+
+        # Print the checks as JSON.
+        scribe doctor dest-destination --dest-namespace dest --source-namespace source -o json
+    `)
+)
+
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorCheck is the result of a check of a replication pair.
+type doctorCheck struct {
+	Check   string `json:"check"`
+	Side    string `json:"side"`
+	Result  string `json:"result"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+type doctorOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	Output        string
+	genericclioptions.IOStreams
+}
+
+func NewDoctorOptions(streams genericclioptions.IOStreams) *doctorOptions {
+	return &doctorOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeDoctor(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewDoctorOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "doctor [NAME] [OPTIONS]",
+		Short:   i18n.T("Diagnose a replication pair, with hints to fix the checks that fail."),
+		Long:    fmt.Sprintf(scribeDoctorLong),
+		Example: fmt.Sprintf(scribeDoctorExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Doctor())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *doctorOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "output format; one of 'json'. If not set, print a table.")
+}
+
+func (o *doctorOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *doctorOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// Validate validates doctor options.
+func (o *doctorOptions) Validate() error {
+	switch o.Output {
+	case "", "json":
+		return nil
+	}
+	return fmt.Errorf("unrecognized --output %s; one of 'json'", o.Output)
+}
+
+// Doctor runs the checks of the pair, prints them and returns an error if one fails.
+func (o *doctorOptions) Doctor() error {
+	ctx := context.Background()
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	checks, err := o.runChecks(ctx, pair)
+	if err != nil {
+		return err
+	}
+	if o.Output == "json" {
+		data, err := json.MarshalIndent(checks, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+	} else if err := printDoctorChecks(o.Out, checks); err != nil {
+		return err
+	}
+	failed := 0
+	for _, check := range checks {
+		if check.Result == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks of ReplicationDestination %s failed", failed, len(checks), pair.Destination.Name)
+	}
+	return nil
+}
+
+// runChecks runs the catalog of checks of the pair. Checks of a side that cannot be read, such as
+// VolumeSnapshots without the snapshot CRDs, are skipped.
+func (o *doctorOptions) runChecks(ctx context.Context, pair *replicationPair) ([]doctorCheck, error) {
+	checks := o.checkPair(pair)
+	for _, side := range o.scribeOptions.pairSides(pair) {
+		checks = append(checks, checkConditions(side)...)
+		for _, run := range []func(context.Context, pairSide) ([]doctorCheck, error){checkMovers, checkVolumes, checkSnapshots} {
+			sideChecks, err := run(ctx, side)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %v", side.kind, side.object.GetName(), err)
+			}
+			checks = append(checks, sideChecks...)
+		}
+	}
+	if pair.Destination.Spec.Rsync != nil {
+		serviceChecks, err := o.checkService(ctx, pair)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, serviceChecks...)
+	}
+	if pair.Source != nil && pair.Source.Spec.Rsync != nil && pair.Destination.Spec.Rsync != nil {
+		sshChecks, err := o.checkSSHKeys(ctx, pair)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, sshChecks...)
+	}
+	return checks, nil
+}
+
+// checkPair checks that a ReplicationSource replicates to the ReplicationDestination and that neither is paused.
+func (o *doctorOptions) checkPair(pair *replicationPair) []doctorCheck {
+	rd := pair.Destination
+	rs := pair.Source
+	if rs == nil {
+		return []doctorCheck{{
+			Check:   "pair",
+			Side:    "source",
+			Result:  doctorFail,
+			Message: fmt.Sprintf("no ReplicationSource in namespace %s of context %s replicates to ReplicationDestination %s", o.scribeOptions.sourceNamespace, o.scribeOptions.sourceKubeContext, rd.Name),
+			Hint:    fmt.Sprintf("check --source-namespace and --source-kube-context, or create one with 'scribe new-source --from-destination %s'", rd.Name),
+		}}
+	}
+	checks := []doctorCheck{{
+		Check:   "pair",
+		Side:    "source",
+		Result:  doctorPass,
+		Message: fmt.Sprintf("ReplicationSource %s replicates to ReplicationDestination %s", rs.Name, rd.Name),
+	}}
+	for _, paused := range []struct {
+		side, kind, name string
+		paused           bool
+	}{
+		{"source", "ReplicationSource", rs.Name, rs.Spec.Paused},
+		{"destination", "ReplicationDestination", rd.Name, rd.Spec.Paused},
+	} {
+		check := doctorCheck{Check: "paused", Side: paused.side, Result: doctorPass, Message: fmt.Sprintf("%s %s is not paused", paused.kind, paused.name)}
+		if paused.paused {
+			check.Result = doctorWarn
+			check.Message = fmt.Sprintf("%s %s is paused", paused.kind, paused.name)
+			check.Hint = fmt.Sprintf("resume the pair with 'scribe resume %s'", rd.Name)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkConditions reports the conditions of the side that are not met.
+func checkConditions(side pairSide) []doctorCheck {
+	var failing []string
+	switch obj := side.object.(type) {
+	case *scribev1alpha1.ReplicationSource:
+		if obj.Status != nil {
+			failing = failingConditions(side.kind, obj.Status.Conditions)
+		}
+	case *scribev1alpha1.ReplicationDestination:
+		if obj.Status != nil {
+			failing = failingConditions(side.kind, obj.Status.Conditions)
+		}
+	}
+	if len(failing) == 0 {
+		return []doctorCheck{{Check: "conditions", Side: side.name, Result: doctorPass, Message: fmt.Sprintf("the conditions of %s %s are met", side.kind, side.object.GetName())}}
+	}
+	checks := []doctorCheck{}
+	for _, condition := range failing {
+		checks = append(checks, doctorCheck{
+			Check:   "conditions",
+			Side:    side.name,
+			Result:  doctorFail,
+			Message: condition,
+			Hint:    "see the events of the pair with 'scribe describe'",
+		})
+	}
+	return checks
+}
+
+// checkMovers reports the mover Jobs of the side that failed and their Pods that cannot run.
+func checkMovers(ctx context.Context, side pairSide) ([]doctorCheck, error) {
+	jobs, err := side.moverJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := side.moverPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checks := []doctorCheck{}
+	for _, job := range jobs {
+		logsHint := fmt.Sprintf("see the logs of the mover with 'kubectl --context %s -n %s logs job/%s'", side.kubeContext, job.Namespace, job.Name)
+		switch condition := jobFailed(&job); {
+		case condition != nil:
+			checks = append(checks, doctorCheck{Check: "mover", Side: side.name, Result: doctorFail, Message: fmt.Sprintf("Job %s failed: %s: %s", job.Name, condition.Reason, condition.Message), Hint: logsHint})
+		case job.Status.Failed > 0:
+			checks = append(checks, doctorCheck{Check: "mover", Side: side.name, Result: doctorWarn, Message: fmt.Sprintf("Job %s is retrying after %d failed Pods", job.Name, job.Status.Failed), Hint: logsHint})
+		default:
+			checks = append(checks, doctorCheck{Check: "mover", Side: side.name, Result: doctorPass, Message: fmt.Sprintf("Job %s has not failed", job.Name)})
+		}
+	}
+	for _, pod := range pods {
+		if problem := podProblem(&pod); len(problem) > 0 {
+			checks = append(checks, doctorCheck{
+				Check:   "mover",
+				Side:    side.name,
+				Result:  doctorFail,
+				Message: fmt.Sprintf("Pod %s %s", pod.Name, problem),
+				Hint:    fmt.Sprintf("see why with 'kubectl --context %s -n %s describe pod %s'", side.kubeContext, pod.Namespace, pod.Name),
+			})
+		}
+	}
+	if len(jobs) == 0 {
+		checks = append(checks, doctorCheck{Check: "mover", Side: side.name, Result: doctorPass, Message: fmt.Sprintf("no mover Job of %s %s is running", side.kind, side.object.GetName())})
+	}
+	return checks, nil
+}
+
+// jobFailed returns the Failed condition of the Job if it is true.
+func jobFailed(job *batchv1.Job) *batchv1.JobCondition {
+	for i := range job.Status.Conditions {
+		if job.Status.Conditions[i].Type == batchv1.JobFailed && job.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// podProblem returns why the Pod cannot run, or an empty string.
+func podProblem(pod *corev1.Pod) string {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return fmt.Sprintf("cannot be scheduled: %s", condition.Message)
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil {
+			switch waiting.Reason {
+			case "CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "InvalidImageName", "CreateContainerError", "CreateContainerConfigError":
+				return fmt.Sprintf("container %s is waiting: %s: %s", status.Name, waiting.Reason, waiting.Message)
+			}
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			return fmt.Sprintf("container %s exited with code %d: %s", status.Name, terminated.ExitCode, terminated.Reason)
+		}
+	}
+	if pod.Status.Phase == corev1.PodFailed {
+		return fmt.Sprintf("failed: %s: %s", pod.Status.Reason, pod.Status.Message)
+	}
+	return ""
+}
+
+// checkVolumes reports the volumes of the side that are not Bound: the volume to replicate and the
+// volumes created by the operator.
+func checkVolumes(ctx context.Context, side pairSide) ([]doctorCheck, error) {
+	c := side.client
+	namespace := side.object.GetNamespace()
+	names := []string{}
+	switch obj := side.object.(type) {
+	case *scribev1alpha1.ReplicationSource:
+		names = append(names, obj.Spec.SourcePVC)
+	case *scribev1alpha1.ReplicationDestination:
+		switch {
+		case obj.Spec.Rsync != nil && obj.Spec.Rsync.DestinationPVC != nil:
+			names = append(names, *obj.Spec.Rsync.DestinationPVC)
+		case obj.Spec.Rclone != nil && obj.Spec.Rclone.DestinationPVC != nil:
+			names = append(names, *obj.Spec.Rclone.DestinationPVC)
+		}
+	}
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, pvc := range pvcs.Items {
+		if ownedBy(&pvc, side.object) {
+			names = append(names, pvc.Name)
+		}
+	}
+	checks := []doctorCheck{}
+	for _, name := range names {
+		check := doctorCheck{Check: "volume", Side: side.name}
+		hint := fmt.Sprintf("see why with 'kubectl --context %s -n %s describe pvc %s'", side.kubeContext, namespace, name)
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pvc)
+		switch {
+		case kerrors.IsNotFound(err):
+			check.Result, check.Message = doctorFail, fmt.Sprintf("PersistentVolumeClaim %s not found", name)
+			check.Hint = fmt.Sprintf("create it, or point %s %s to an existing one", side.kind, side.object.GetName())
+		case err != nil:
+			return nil, err
+		case pvc.Status.Phase == corev1.ClaimBound:
+			check.Result, check.Message = doctorPass, fmt.Sprintf("PersistentVolumeClaim %s is Bound", name)
+		case pvc.Status.Phase == corev1.ClaimPending && waitsForFirstConsumer(ctx, c, pvc):
+			check.Result, check.Message = doctorPass, fmt.Sprintf("PersistentVolumeClaim %s is Pending until a Pod uses it", name)
+		case pvc.Status.Phase == corev1.ClaimPending:
+			check.Result, check.Message, check.Hint = doctorFail, fmt.Sprintf("PersistentVolumeClaim %s is stuck Pending", name), hint
+		default:
+			check.Result, check.Message, check.Hint = doctorFail, fmt.Sprintf("PersistentVolumeClaim %s is %s", name, pvc.Status.Phase), hint
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkSnapshots reports the VolumeSnapshots of the side that are not ReadyToUse: the ones created by the
+// operator and the latest image of a ReplicationDestination.
+func checkSnapshots(ctx context.Context, side pairSide) ([]doctorCheck, error) {
+	namespace := side.object.GetNamespace()
+	latestImage := ""
+	if rd, ok := side.object.(*scribev1alpha1.ReplicationDestination); ok && rd.Status != nil && rd.Status.LatestImage != nil && rd.Status.LatestImage.Kind == "VolumeSnapshot" {
+		latestImage = rd.Status.LatestImage.Name
+	}
+	snapshots, err := listVolumeSnapshots(ctx, side.client, namespace)
+	if meta.IsNoMatchError(err) || kerrors.IsForbidden(err) {
+		// no snapshot CRDs, or no access to them
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checks := []doctorCheck{}
+	for _, snapshot := range snapshots {
+		if !ownedBy(&snapshot, side.object) && snapshot.GetName() != latestImage {
+			continue
+		}
+		check := doctorCheck{Check: "snapshot", Side: side.name, Result: doctorPass, Message: fmt.Sprintf("VolumeSnapshot %s is ReadyToUse", snapshot.GetName())}
+		ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		if !ready {
+			check.Result = doctorWarn
+			check.Message = fmt.Sprintf("VolumeSnapshot %s is not ReadyToUse yet", snapshot.GetName())
+			if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+				check.Result = doctorFail
+				check.Message = fmt.Sprintf("VolumeSnapshot %s is not ReadyToUse: %s", snapshot.GetName(), message)
+			}
+			check.Hint = fmt.Sprintf("check the CSI snapshot controller and the VolumeSnapshotClass with 'kubectl --context %s -n %s describe volumesnapshot %s'", side.kubeContext, namespace, snapshot.GetName())
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkService checks that the rsync Service of the ReplicationDestination has an address and that the
+// ReplicationSource connects to the address published by the ReplicationDestination.
+func (o *doctorOptions) checkService(ctx context.Context, pair *replicationPair) ([]doctorCheck, error) {
+	rd := pair.Destination
+	services := &corev1.ServiceList{}
+	if err := o.scribeOptions.DestinationClient.List(ctx, services, client.InNamespace(rd.Namespace)); err != nil {
+		return nil, err
+	}
+	checks := []doctorCheck{}
+	for _, service := range services.Items {
+		if !ownedBy(&service, rd) {
+			continue
+		}
+		check := doctorCheck{Check: "service", Side: "destination", Result: doctorPass, Message: fmt.Sprintf("Service %s is %s", service.Name, service.Spec.Type)}
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			if len(service.Status.LoadBalancer.Ingress) == 0 {
+				check.Result = doctorFail
+				check.Message = fmt.Sprintf("LoadBalancer Service %s has no address", service.Name)
+				check.Hint = fmt.Sprintf("the cluster of context %s needs a load balancer, such as MetalLB on bare metal; use --dest-service-type ClusterIP when both sides are in the same cluster", o.scribeOptions.destKubeContext)
+			} else {
+				check.Message = fmt.Sprintf("LoadBalancer Service %s has an address", service.Name)
+			}
+		}
+		checks = append(checks, check)
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{
+			Check:   "service",
+			Side:    "destination",
+			Result:  doctorWarn,
+			Message: fmt.Sprintf("ReplicationDestination %s has no rsync Service yet", rd.Name),
+			Hint:    "check that the scribe operator is running in the destination cluster",
+		})
+	}
+	rs := pair.Source
+	if rs == nil || rs.Spec.Rsync == nil || !destinationHasAddress(rd) {
+		return checks, nil
+	}
+	address := *rd.Status.Rsync.Address
+	check := doctorCheck{Check: "address", Side: "source", Result: doctorPass, Message: fmt.Sprintf("ReplicationSource %s connects to %s", rs.Name, address)}
+	if rs.Spec.Rsync.Address == nil || *rs.Spec.Rsync.Address != address {
+		check.Result = doctorFail
+		check.Message = fmt.Sprintf("ReplicationSource %s connects to %s, not to the address %s of the ReplicationDestination", rs.Name, valueOrNone(rs.Spec.Rsync.Address), address)
+		check.Hint = fmt.Sprintf("scribe update-source %s --source-namespace %s --address %s", rs.Name, rs.Namespace, address)
+	}
+	return append(checks, check), nil
+}
+
+// checkSSHKeys checks that the SSH keys secret of the ReplicationSource has the keys of the secret of the
+// ReplicationDestination.
+func (o *doctorOptions) checkSSHKeys(ctx context.Context, pair *replicationPair) ([]doctorCheck, error) {
+	rd := pair.Destination
+	rs := pair.Source
+	name := destinationSSHKeysSecret(rd)
+	if rs.Spec.Rsync.SSHKeys != nil {
+		name = *rs.Spec.Rsync.SSHKeys
+	}
+	check := doctorCheck{Check: "ssh-keys", Side: "source"}
+	syncHint := fmt.Sprintf("copy the secret with 'scribe sync-ssh-secret --dest-namespace %s --source-namespace %s --ssh-keys-secret %s'", rd.Namespace, rs.Namespace, destinationSSHKeysSecret(rd))
+	destSecret := &corev1.Secret{}
+	err := o.scribeOptions.DestinationClient.Get(ctx, types.NamespacedName{Namespace: rd.Namespace, Name: destinationSSHKeysSecret(rd)}, destSecret)
+	switch {
+	case kerrors.IsNotFound(err):
+		check.Side = "destination"
+		check.Result, check.Message = doctorWarn, fmt.Sprintf("ReplicationDestination %s has not created its SSH keys secret %s yet", rd.Name, destinationSSHKeysSecret(rd))
+		return []doctorCheck{check}, nil
+	case err != nil:
+		return nil, err
+	}
+	sourceSecret := &corev1.Secret{}
+	err = o.scribeOptions.SourceClient.Get(ctx, types.NamespacedName{Namespace: rs.Namespace, Name: name}, sourceSecret)
+	switch {
+	case kerrors.IsNotFound(err):
+		check.Result, check.Message, check.Hint = doctorFail, fmt.Sprintf("SSH keys secret %s not found in namespace %s", name, rs.Namespace), syncHint
+	case err != nil:
+		return nil, err
+	case !reflect.DeepEqual(sourceSecret.Data, destSecret.Data):
+		check.Result = doctorFail
+		check.Message = fmt.Sprintf("SSH keys secret %s differs from secret %s of the ReplicationDestination", name, destSecret.Name)
+		check.Hint = fmt.Sprintf("delete secret %s in namespace %s of context %s, then %s", name, rs.Namespace, o.scribeOptions.sourceKubeContext, syncHint)
+	default:
+		check.Result, check.Message = doctorPass, fmt.Sprintf("SSH keys secret %s matches the secret of the ReplicationDestination", name)
+	}
+	return []doctorCheck{check}, nil
+}
+
+// printDoctorChecks prints the checks as a table, followed by the hints of the checks that did not pass.
+func printDoctorChecks(out io.Writer, checks []doctorCheck) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "RESULT\tSIDE\tCHECK\tMESSAGE")
+	for _, check := range checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", check.Result, check.Side, check.Check, check.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	hints := []string{}
+	for _, check := range checks {
+		if check.Result != doctorPass && len(check.Hint) > 0 {
+			hints = append(hints, fmt.Sprintf("  %s (%s): %s", check.Check, check.Side, check.Hint))
+		}
+	}
+	if len(hints) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nHints:")
+	for _, hint := range hints {
+		fmt.Fprintln(out, hint)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeEventsLong = templates.LongDesc(`
+Show the events of a replication pair from the source and destination clusters in one timeline, oldest
+first. The events are the ones of the ReplicationSource and the ReplicationDestination, of their mover Jobs
+and Pods, of the volume to replicate and the volumes, VolumeSnapshots and rsync Service created by the
+scribe operator, including those of past syncs.
+
+With --watch, the new events of both clusters are printed as they happen, until interrupted.
+
+The pair is selected as for status.
+`)
+	scribeEventsExample = templates.Examples(`
+        # Show the events of the ReplicationDestination 'dest-destination' and its ReplicationSource.
+        scribe events dest-destination --dest-namespace dest --source-namespace source
+
+        # Watch the events of a pair across clusters.
+        scribe events dest-destination --dest-kube-context kind-kind --source-kube-context admin --watch
+    `)
+)
+
+type eventsOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	Watch         bool
+	genericclioptions.IOStreams
+}
+
+func NewEventsOptions(streams genericclioptions.IOStreams) *eventsOptions {
+	return &eventsOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeEvents(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewEventsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "events [NAME] [OPTIONS]",
+		Short:   i18n.T("Show the events of a replication pair from both clusters in one timeline."),
+		Long:    fmt.Sprintf(scribeEventsLong),
+		Example: fmt.Sprintf(scribeEventsExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Events())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *eventsOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.Watch, "watch", "w", o.Watch, "after printing the events, print the new events as they happen until interrupted.")
+}
+
+func (o *eventsOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *eventsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// pairEvent is an event of a side of a pair.
+type pairEvent struct {
+	side  string
+	event corev1.Event
+}
+
+// Events prints the events of the pair, oldest first, then the new events with --watch.
+func (o *eventsOptions) Events() error {
+	ctx := context.Background()
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	if pair.Source == nil {
+		klog.Warningf("no ReplicationSource in namespace %s replicates to ReplicationDestination %s, showing the events of the destination only", o.scribeOptions.sourceNamespace, pair.Destination.Name)
+	}
+	sides := o.scribeOptions.pairSides(pair)
+	printed := map[string]bool{}
+	events, err := newPairEvents(ctx, sides, printed)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 && !o.Watch {
+		klog.V(0).Infof("no events found for ReplicationDestination %s", pair.Destination.Name)
+		return nil
+	}
+	if err := printPairEvents(o.Out, events, true); err != nil {
+		return err
+	}
+	if !o.Watch {
+		return nil
+	}
+	ctx, cancel := interruptContext()
+	defer cancel()
+	header := len(events) == 0
+	err = wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		events, err := newPairEvents(ctx, sides, printed)
+		if err != nil {
+			if ctx.Err() == nil {
+				klog.Errorf("%v", err)
+			}
+			return false, nil
+		}
+		if len(events) == 0 {
+			return false, nil
+		}
+		err = printPairEvents(o.Out, events, header)
+		header = false
+		return false, err
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		// interrupted
+		return nil
+	}
+	return err
+}
+
+// newPairEvents returns the events of the sides that are not in printed, sorted by time, and sets printed to
+// the events listed. An event is printed again when it is updated, such as when it repeats, and the events
+// that are no longer listed are forgotten. printed is left unchanged if the events of a side cannot be listed.
+func newPairEvents(ctx context.Context, sides []pairSide, printed map[string]bool) ([]pairEvent, error) {
+	listed := map[string]bool{}
+	newEvents := []pairEvent{}
+	for _, side := range sides {
+		events := &corev1.EventList{}
+		if err := side.client.List(ctx, events, client.InNamespace(side.object.GetNamespace())); err != nil {
+			return nil, fmt.Errorf("unable to list the events of the %s: %v", side.name, err)
+		}
+		related := side.relatedObjects()
+		for _, event := range events.Items {
+			if !isRelatedEvent(&event, related) {
+				continue
+			}
+			key := string(event.UID) + "/" + event.ResourceVersion
+			listed[key] = true
+			if !printed[key] {
+				newEvents = append(newEvents, pairEvent{side: side.name, event: event})
+			}
+		}
+	}
+	for key := range printed {
+		if !listed[key] {
+			delete(printed, key)
+		}
+	}
+	for key := range listed {
+		printed[key] = true
+	}
+	sort.SliceStable(newEvents, func(i, j int) bool {
+		return eventTime(&newEvents[i].event).Before(eventTime(&newEvents[j].event))
+	})
+	return newEvents, nil
+}
+
+// relatedObject is the kind and name, or name prefix, of an object whose events belong to a side.
+type relatedObject struct {
+	kind   string
+	name   string
+	prefix bool
+}
+
+// relatedObjects returns the objects whose events belong to the side, by the names the scribe operator
+// gives to the objects it creates, so that the events of the objects of past syncs are found too.
+func (s *pairSide) relatedObjects() []relatedObject {
+	name := s.object.GetName()
+	related := []relatedObject{{kind: s.kind, name: name}}
+	switch obj := s.object.(type) {
+	case *scribev1alpha1.ReplicationSource:
+		related = append(related,
+			relatedObject{kind: "PersistentVolumeClaim", name: obj.Spec.SourcePVC},
+			relatedObject{kind: "PersistentVolumeClaim", name: "scribe-src-" + name},
+			relatedObject{kind: "VolumeSnapshot", name: "scribe-src-" + name},
+		)
+		for _, job := range []string{"scribe-rsync-src-" + name, "scribe-rclone-src-" + name} {
+			related = append(related, relatedObject{kind: "Job", name: job}, relatedObject{kind: "Pod", name: job + "-", prefix: true})
+		}
+	case *scribev1alpha1.ReplicationDestination:
+		destPVC := "scribe-dest-" + name
+		switch {
+		case obj.Spec.Rsync != nil && obj.Spec.Rsync.DestinationPVC != nil:
+			destPVC = *obj.Spec.Rsync.DestinationPVC
+		case obj.Spec.Rclone != nil && obj.Spec.Rclone.DestinationPVC != nil:
+			destPVC = *obj.Spec.Rclone.DestinationPVC
+		}
+		related = append(related,
+			relatedObject{kind: "PersistentVolumeClaim", name: destPVC},
+			relatedObject{kind: "VolumeSnapshot", name: "scribe-dest-" + name + "-", prefix: true},
+			relatedObject{kind: "Service", name: "scribe-rsync-dest-" + name},
+		)
+		// scribe names the rclone Job of a ReplicationDestination like the one of a ReplicationSource
+		for _, job := range []string{"scribe-rsync-dest-" + name, "scribe-rclone-src-" + name} {
+			related = append(related, relatedObject{kind: "Job", name: job}, relatedObject{kind: "Pod", name: job + "-", prefix: true})
+		}
+	}
+	return related
+}
+
+// isRelatedEvent returns true if the event is about one of the related objects.
+func isRelatedEvent(event *corev1.Event, related []relatedObject) bool {
+	for _, r := range related {
+		if event.InvolvedObject.Kind != r.kind {
+			continue
+		}
+		if event.InvolvedObject.Name == r.name || (r.prefix && strings.HasPrefix(event.InvolvedObject.Name, r.name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTime returns the last time the event happened.
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// printPairEvents prints the events as a table, with the header if requested.
+func printPairEvents(out io.Writer, events []pairEvent, header bool) error {
+	w := printers.GetNewTabWriter(out)
+	if header {
+		fmt.Fprintln(w, "TIME\tSIDE\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	}
+	for _, e := range events {
+		object := strings.ToLower(e.event.InvolvedObject.Kind) + "/" + e.event.InvolvedObject.Name
+		message := strings.TrimSpace(e.event.Message)
+		if e.event.Count > 1 {
+			message = fmt.Sprintf("%s (x%d)", message, e.event.Count)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", eventTime(&e.event).Local().Format(time.RFC3339), e.side, e.event.Type, e.event.Reason, object, message)
+	}
+	return w.Flush()
+}
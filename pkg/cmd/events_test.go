@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+func TestIsRelatedEvent(t *testing.T) {
+	destPVC := "db-copy"
+	rs := &scribev1alpha1.ReplicationSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-source", Namespace: "db"},
+		Spec:       scribev1alpha1.ReplicationSourceSpec{SourcePVC: "mysql-pv-claim"},
+	}
+	rd := &scribev1alpha1.ReplicationDestination{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-destination", Namespace: "db"},
+	}
+	rdWithPVC := &scribev1alpha1.ReplicationDestination{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-destination", Namespace: "db"},
+		Spec: scribev1alpha1.ReplicationDestinationSpec{
+			Rsync: &scribev1alpha1.ReplicationDestinationRsyncSpec{
+				ReplicationDestinationVolumeOptions: scribev1alpha1.ReplicationDestinationVolumeOptions{DestinationPVC: &destPVC},
+			},
+		},
+	}
+	source := &pairSide{name: "source", kind: "ReplicationSource", object: rs}
+	destination := &pairSide{name: "destination", kind: "ReplicationDestination", object: rd}
+	destinationWithPVC := &pairSide{name: "destination", kind: "ReplicationDestination", object: rdWithPVC}
+
+	tests := []struct {
+		name string
+		side *pairSide
+		kind string
+		obj  string
+		want bool
+	}{
+		{"ReplicationSource", source, "ReplicationSource", "db-source", true},
+		{"other ReplicationSource", source, "ReplicationSource", "web-source", false},
+		{"ReplicationDestination of the other side", source, "ReplicationDestination", "db-destination", false},
+		{"volume to replicate", source, "PersistentVolumeClaim", "mysql-pv-claim", true},
+		{"source image volume", source, "PersistentVolumeClaim", "scribe-src-db-source", true},
+		{"source snapshot", source, "VolumeSnapshot", "scribe-src-db-source", true},
+		{"rsync Job", source, "Job", "scribe-rsync-src-db-source", true},
+		{"rsync Pod", source, "Pod", "scribe-rsync-src-db-source-x7k2p", true},
+		{"rclone Pod", source, "Pod", "scribe-rclone-src-db-source-x7k2p", true},
+		{"name matched as a prefix of another kind", source, "Job", "scribe-rsync-src-db-source-x7k2p", false},
+		{"Pod of another Job", source, "Pod", "scribe-rsync-src-db-source2", false},
+		{"ReplicationDestination", destination, "ReplicationDestination", "db-destination", true},
+		{"destination volume", destination, "PersistentVolumeClaim", "scribe-dest-db-destination", true},
+		{"destination snapshot", destination, "VolumeSnapshot", "scribe-dest-db-destination-20210301120000", true},
+		{"rsync Service", destination, "Service", "scribe-rsync-dest-db-destination", true},
+		{"rsync destination Pod", destination, "Pod", "scribe-rsync-dest-db-destination-q9z4m", true},
+		{"rclone destination Job named like a source Job", destination, "Job", "scribe-rclone-src-db-destination", true},
+		{"volume of the ReplicationDestination", destinationWithPVC, "PersistentVolumeClaim", "db-copy", true},
+		{"default volume with a volume set", destinationWithPVC, "PersistentVolumeClaim", "scribe-dest-db-destination", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &corev1.Event{InvolvedObject: corev1.ObjectReference{Kind: tt.kind, Name: tt.obj}}
+			if got := isRelatedEvent(event, tt.side.relatedObjects()); got != tt.want {
+				t.Errorf("expected %v for the event of %s %s, got %v", tt.want, tt.kind, tt.obj, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeExportLong = templates.LongDesc(`
+Write the replication pairs set up in the source and destination clusters to a ReplicationPlan file
+that apply can use to recreate them, or to a scribe-config for a single pair.
+
+The ReplicationSources and ReplicationDestinations are listed as get lists them. A ReplicationSource
+is paired with the ReplicationDestination whose published address or SSH keys secret it uses, or, with
+rclone, whose remote path and config section it uses. Only the spec fields new-source and new-destination
+set are exported; status and the fields managed by the server are left out. The address and SSH keys
+secret a ReplicationSource got from its ReplicationDestination are left out as well, so that apply
+connects the ReplicationSource to the ReplicationDestination it creates.
+
+Objects that are not part of a pair, and pairs using an external provider, are reported and skipped.
+A paused object is exported with the schedule it will get back when resumed.
+`)
+	scribeExportExample = templates.Examples(`
+        # Print the plan of the pairs between the namespaces of scribe-config.
+        scribe export
+
+        # Write the plan of the pairs in all namespaces of two clusters to plan.yaml.
+        scribe export --all-namespaces --source-kube-context admin --dest-kube-context kind-kind -f plan.yaml
+
+        # Write the scribe-config of the pair whose objects are labeled app=mysql.
+        scribe export -l app=mysql --format config -f scribe-config.yaml
+    `)
+)
+
+type exportOptions struct {
+	scribeOptions scribeOptions
+	AllNamespaces bool
+	LabelSelector string
+	Format        string
+	Filename      string
+	genericclioptions.IOStreams
+}
+
+func NewExportOptions(streams genericclioptions.IOStreams) *exportOptions {
+	return &exportOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeExport(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewExportOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "export [OPTIONS]",
+		Short:   i18n.T("Write the existing replication pairs to a ReplicationPlan file or a scribe-config."),
+		Long:    fmt.Sprintf(scribeExportLong),
+		Example: fmt.Sprintf(scribeExportExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete())
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Export())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *exportOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "if present, export the pairs across all namespaces of both clusters. Namespaces in --source-namespace and --dest-namespace are ignored.")
+	flags.StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	flags.StringVar(&o.Format, "format", "plan", "the format to write; one of 'plan|config'. A config holds a single pair.")
+	flags.StringVarP(&o.Filename, "filename", "f", "-", "the file to write, '-' to write to stdout.")
+}
+
+func (o *exportOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *exportOptions) Complete() error {
+	return o.scribeOptions.Complete()
+}
+
+// Validate validates export options.
+func (o *exportOptions) Validate() error {
+	if o.Format != "plan" && o.Format != "config" {
+		return fmt.Errorf("--format must be one of 'plan|config', got %s", o.Format)
+	}
+	return nil
+}
+
+// Export writes the pairs found in both clusters in the requested format.
+func (o *exportOptions) Export() error {
+	plan, err := o.exportPlan(context.Background())
+	if err != nil {
+		return err
+	}
+	var exported interface{} = plan
+	if o.Format == "config" {
+		if len(plan.Pairs) != 1 {
+			return fmt.Errorf("a config holds a single pair, found %d pairs; select one with -l or the namespace flags", len(plan.Pairs))
+		}
+		exported = plan.Pairs[0].config()
+	}
+	data, err := yaml.Marshal(exported)
+	if err != nil {
+		return err
+	}
+	if o.Filename == "-" {
+		_, err = o.Out.Write(data)
+		return err
+	}
+	if err := ioutil.WriteFile(o.Filename, data, 0644); err != nil {
+		return err
+	}
+	klog.V(0).Infof("%d pairs written to %s", len(plan.Pairs), o.Filename)
+	return nil
+}
+
+// exportPlan lists the objects of both clusters and returns the plan of the pairs they form.
+func (o *exportOptions) exportPlan(ctx context.Context) (*replicationPlan, error) {
+	g := &getOptions{AllNamespaces: o.AllNamespaces, LabelSelector: o.LabelSelector}
+	sourceOpts, err := g.listOptions(o.scribeOptions.sourceNamespace)
+	if err != nil {
+		return nil, err
+	}
+	destOpts, err := g.listOptions(o.scribeOptions.destNamespace)
+	if err != nil {
+		return nil, err
+	}
+	repSources := &scribev1alpha1.ReplicationSourceList{}
+	if err := o.scribeOptions.SourceClient.List(ctx, repSources, sourceOpts...); err != nil {
+		return nil, err
+	}
+	repDests := &scribev1alpha1.ReplicationDestinationList{}
+	if err := o.scribeOptions.DestinationClient.List(ctx, repDests, destOpts...); err != nil {
+		return nil, err
+	}
+
+	plan := &replicationPlan{
+		APIVersion: replicationPlanAPIVersion,
+		Kind:       replicationPlanKind,
+		Pairs:      []replicationPlanPair{},
+	}
+	names := map[string]bool{}
+	paired := map[int]bool{}
+	for i := range repDests.Items {
+		rd := &repDests.Items[i]
+		var rs *scribev1alpha1.ReplicationSource
+		for j := range repSources.Items {
+			if !paired[j] && sourceMatchesDestination(&repSources.Items[j], rd) {
+				paired[j] = true
+				rs = &repSources.Items[j]
+				break
+			}
+		}
+		if rs == nil {
+			klog.Warningf("skipping ReplicationDestination %s in namespace %s: no ReplicationSource replicates to it", rd.Name, rd.Namespace)
+			continue
+		}
+		pair := o.exportPair(rs, rd)
+		if names[pair.Name] {
+			pair.Name = rs.Namespace + "-" + rs.Name
+		}
+		if err := pair.validate(); err != nil {
+			klog.Warningf("skipping ReplicationSource %s in namespace %s and ReplicationDestination %s in namespace %s: %v", rs.Name, rs.Namespace, rd.Name, rd.Namespace, err)
+			continue
+		}
+		names[pair.Name] = true
+		plan.Pairs = append(plan.Pairs, *pair)
+	}
+	for j := range repSources.Items {
+		if !paired[j] {
+			rs := &repSources.Items[j]
+			klog.Warningf("skipping ReplicationSource %s in namespace %s: it does not replicate to a listed ReplicationDestination", rs.Name, rs.Namespace)
+		}
+	}
+	return plan, nil
+}
+
+// exportPair returns the plan of the pair formed by the objects, leaving out what the ReplicationSource
+// got from the ReplicationDestination.
+func (o *exportOptions) exportPair(rs *scribev1alpha1.ReplicationSource, rd *scribev1alpha1.ReplicationDestination) *replicationPlanPair {
+	pair := &replicationPlanPair{
+		Name: rs.Name,
+		Source: replicationSide{
+			KubeContext: o.scribeOptions.sourceKubeContext,
+			ClusterName: o.scribeOptions.sourceKubeClusterName,
+			Namespace:   rs.Namespace,
+			Name:        rs.Name,
+			PVC:         rs.Spec.SourcePVC,
+		},
+		Destination: replicationSide{
+			KubeContext: o.scribeOptions.destKubeContext,
+			ClusterName: o.scribeOptions.destKubeClusterName,
+			Namespace:   rd.Namespace,
+			Name:        rd.Name,
+		},
+	}
+	var schedule *string
+	if rs.Spec.Trigger != nil {
+		schedule = rs.Spec.Trigger.Schedule
+	}
+	pair.Source.Schedule = exportSchedule(schedule, rs.Annotations)
+	schedule = nil
+	if rd.Spec.Trigger != nil {
+		schedule = rd.Spec.Trigger.Schedule
+	}
+	pair.Destination.Schedule = exportSchedule(schedule, rd.Annotations)
+
+	switch {
+	case rs.Spec.Rsync != nil && rd.Spec.Rsync != nil:
+		s, d := rs.Spec.Rsync, rd.Spec.Rsync
+		exportVolumeOptions(&pair.Source, s.CopyMethod, s.Capacity, s.StorageClassName, s.AccessModes, s.VolumeSnapshotClassName)
+		exportVolumeOptions(&pair.Destination, d.CopyMethod, d.Capacity, d.StorageClassName, d.AccessModes, d.VolumeSnapshotClassName)
+		pair.Destination.PVC = stringValue(d.DestinationPVC)
+		exportRsync(&pair.Source, s.SSHKeys, s.ServiceType, s.Address, s.Port, s.Path, s.SSHUser)
+		exportRsync(&pair.Destination, d.SSHKeys, d.ServiceType, d.Address, d.Port, d.Path, d.SSHUser)
+		if stringPtrsEqual(s.Address, rd.Status.Rsync.Address) {
+			pair.Source.Address = ""
+		}
+		if pair.Source.SSHKeysSecret == destinationSSHKeysSecret(rd) {
+			pair.Source.SSHKeysSecret = ""
+		}
+	case rs.Spec.Rclone != nil && rd.Spec.Rclone != nil:
+		s, d := rs.Spec.Rclone, rd.Spec.Rclone
+		pair.Mover = "rclone"
+		pair.Rclone = &replicationRclone{
+			Config:        stringValue(s.RcloneConfig),
+			ConfigSection: stringValue(s.RcloneConfigSection),
+			DestPath:      stringValue(s.RcloneDestPath),
+		}
+		if stringValue(d.RcloneConfig) != pair.Rclone.Config {
+			klog.Warningf("ReplicationDestination %s in namespace %s uses the rclone config %s, exported with the rclone config %s of its ReplicationSource", rd.Name, rd.Namespace, stringValue(d.RcloneConfig), pair.Rclone.Config)
+		}
+		exportVolumeOptions(&pair.Source, s.CopyMethod, s.Capacity, s.StorageClassName, s.AccessModes, s.VolumeSnapshotClassName)
+		exportVolumeOptions(&pair.Destination, d.CopyMethod, d.Capacity, d.StorageClassName, d.AccessModes, d.VolumeSnapshotClassName)
+		pair.Destination.PVC = stringValue(d.DestinationPVC)
+	}
+	return pair
+}
+
+// exportSchedule returns the schedule of an object, or the one it gets back when resumed if it is paused.
+func exportSchedule(schedule *string, annotations map[string]string) string {
+	if recorded, paused := annotations[pausedScheduleAnnotation]; paused {
+		return recorded
+	}
+	return stringValue(schedule)
+}
+
+func exportVolumeOptions(side *replicationSide, copyMethod scribev1alpha1.CopyMethodType, capacity *resource.Quantity, storageClassName *string, accessModes []corev1.PersistentVolumeAccessMode, volumeSnapshotClassName *string) {
+	side.CopyMethod = string(copyMethod)
+	if capacity != nil {
+		side.Capacity = capacity.String()
+	}
+	side.StorageClassName = stringValue(storageClassName)
+	if len(accessModes) > 0 {
+		// new-source and new-destination set a single access mode
+		side.AccessMode = string(accessModes[0])
+	}
+	side.VolumeSnapshotClassName = stringValue(volumeSnapshotClassName)
+}
+
+func exportRsync(side *replicationSide, sshKeys *string, serviceType *corev1.ServiceType, address *string, port *int32, path, sshUser *string) {
+	side.SSHKeysSecret = stringValue(sshKeys)
+	if serviceType != nil {
+		side.ServiceType = string(*serviceType)
+	}
+	side.Address = stringValue(address)
+	if port != nil {
+		side.Port = *port
+	}
+	side.Path = stringValue(path)
+	side.SSHUser = stringValue(sshUser)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// config returns the scribe-config setting the flags of replicate to the values of the pair.
+func (p *replicationPlanPair) config() map[string]interface{} {
+	config := map[string]interface{}{}
+	set := func(key, value string) {
+		if len(value) > 0 {
+			config[key] = value
+		}
+	}
+	set("source-kube-context", p.Source.KubeContext)
+	set("source-kube-clustername", p.Source.ClusterName)
+	set("source-namespace", p.Source.Namespace)
+	set("source-name", p.Source.Name)
+	set("source-pvc", p.Source.PVC)
+	set("source-copy-method", p.Source.CopyMethod)
+	set("source-capacity", p.Source.Capacity)
+	set("source-storage-class-name", p.Source.StorageClassName)
+	set("source-access-mode", p.Source.AccessMode)
+	set("source-volume-snapshot-class", p.Source.VolumeSnapshotClassName)
+	set("source-ssh-user", p.Source.SSHUser)
+	set("source-service-type", p.Source.ServiceType)
+	set("dest-kube-context", p.Destination.KubeContext)
+	set("dest-kube-clustername", p.Destination.ClusterName)
+	set("dest-namespace", p.Destination.Namespace)
+	set("dest-name", p.Destination.Name)
+	set("dest-pvc", p.Destination.PVC)
+	set("dest-copy-method", p.Destination.CopyMethod)
+	set("dest-storage-class-name", p.Destination.StorageClassName)
+	set("dest-access-mode", p.Destination.AccessMode)
+	set("dest-volume-snapshot-class", p.Destination.VolumeSnapshotClassName)
+	set("dest-cron-spec", p.Destination.Schedule)
+	set("dest-ssh-user", p.Destination.SSHUser)
+	set("dest-service-type", p.Destination.ServiceType)
+	set("path", p.Source.Path)
+	// the flags defaulted by the CLI are always set, so that no default is added
+	config["source-cron-spec"] = p.Source.Schedule
+	config["dest-capacity"] = p.Destination.Capacity
+	if p.Source.Port != 0 {
+		config["port"] = p.Source.Port
+	}
+	if p.Mover == "rclone" {
+		config["mover"] = p.Mover
+		set("rclone-config", p.Rclone.Config)
+		set("rclone-config-section", p.Rclone.ConfigSection)
+		set("rclone-dest-path", p.Rclone.DestPath)
+	}
+	return config
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeGetLong = templates.LongDesc(`
+List the ReplicationSources and ReplicationDestinations from both the source and the destination
+kubeconfig contexts in a single table. The source side is read with the source flags and the
+destination side with the destination flags, so the same scribe-config used to create a
+replication can be used to inspect it.
+
+Paused objects are marked in the SCHEDULE column, with the schedule they had before they were paused.
+`)
+	scribeGetExample = templates.Examples(`
+        # List the ReplicationSources in the source namespace and the ReplicationDestinations in the destination namespace.
+        scribe get --source-namespace source --dest-namespace dest
+
+        # List ReplicationSources and ReplicationDestinations in all namespaces of two clusters.
+        scribe get --all-namespaces --source-kube-context admin --dest-kube-context kind-kind
+
+        # List only objects labeled app=mysql.
+        scribe get -l app=mysql
+    `)
+)
+
+type getOptions struct {
+	scribeOptions scribeOptions
+	AllNamespaces bool
+	LabelSelector string
+	genericclioptions.IOStreams
+}
+
+func NewGetOptions(streams genericclioptions.IOStreams) *getOptions {
+	return &getOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeGet(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewGetOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "get [OPTIONS]",
+		Short:   i18n.T("List ReplicationSources and ReplicationDestinations across the source and destination clusters."),
+		Long:    fmt.Sprintf(scribeGetLong),
+		Example: fmt.Sprintf(scribeGetExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete())
+			kcmdutil.CheckErr(o.Get())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *getOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "if present, list the requested objects across all namespaces of both clusters. Namespaces in --source-namespace and --dest-namespace are ignored.")
+	flags.StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+}
+
+func (o *getOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *getOptions) Complete() error {
+	return o.scribeOptions.Complete()
+}
+
+// listOptions returns the list options for one side of the replication.
+func (o *getOptions) listOptions(namespace string) ([]client.ListOption, error) {
+	opts := []client.ListOption{}
+	if !o.AllNamespaces {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if len(o.LabelSelector) > 0 {
+		selector, err := labels.Parse(o.LabelSelector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+	return opts, nil
+}
+
+// Get prints the ReplicationSources and ReplicationDestinations of both clusters.
+func (o *getOptions) Get() error {
+	ctx := context.Background()
+	sourceOpts, err := o.listOptions(o.scribeOptions.sourceNamespace)
+	if err != nil {
+		return err
+	}
+	destOpts, err := o.listOptions(o.scribeOptions.destNamespace)
+	if err != nil {
+		return err
+	}
+	repSources := &scribev1alpha1.ReplicationSourceList{}
+	if err := o.scribeOptions.SourceClient.List(ctx, repSources, sourceOpts...); err != nil {
+		return err
+	}
+	repDests := &scribev1alpha1.ReplicationDestinationList{}
+	if err := o.scribeOptions.DestinationClient.List(ctx, repDests, destOpts...); err != nil {
+		return err
+	}
+	if len(repSources.Items) == 0 && len(repDests.Items) == 0 {
+		fmt.Fprintln(o.ErrOut, "No resources found")
+		return nil
+	}
+	return printReplications(o.Out, o.scribeOptions.sourceKubeContext, repSources.Items, o.scribeOptions.destKubeContext, repDests.Items)
+}
+
+func printReplications(out io.Writer, sourceContext string, repSources []scribev1alpha1.ReplicationSource, destContext string, repDests []scribev1alpha1.ReplicationDestination) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "NAME\tCONTEXT\tNAMESPACE\tCOPY METHOD\tSCHEDULE\tLAST SYNC\tNEXT SYNC\tADDRESS")
+	for _, rs := range repSources {
+		var copyMethod scribev1alpha1.CopyMethodType
+		var address *string
+		switch {
+		case rs.Spec.Rsync != nil:
+			copyMethod = rs.Spec.Rsync.CopyMethod
+			address = rs.Spec.Rsync.Address
+		case rs.Spec.Rclone != nil:
+			copyMethod = rs.Spec.Rclone.CopyMethod
+		}
+		var schedule *string
+		if rs.Spec.Trigger != nil {
+			schedule = rs.Spec.Trigger.Schedule
+		}
+		var lastSync, nextSync *metav1.Time
+		if rs.Status != nil {
+			lastSync = rs.Status.LastSyncTime
+			nextSync = rs.Status.NextSyncTime
+		}
+		fmt.Fprintf(w, "replicationsource/%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			rs.Name, valueOrNone(&sourceContext), rs.Namespace, copyMethod, scheduleColumn(schedule, rs.Spec.Paused, rs.Annotations),
+			translateTimestampSince(lastSync), translateTimestampUntil(nextSync), valueOrNone(address))
+	}
+	for _, rd := range repDests {
+		var copyMethod scribev1alpha1.CopyMethodType
+		switch {
+		case rd.Spec.Rsync != nil:
+			copyMethod = rd.Spec.Rsync.CopyMethod
+		case rd.Spec.Rclone != nil:
+			copyMethod = rd.Spec.Rclone.CopyMethod
+		}
+		var schedule *string
+		if rd.Spec.Trigger != nil {
+			schedule = rd.Spec.Trigger.Schedule
+		}
+		var lastSync, nextSync *metav1.Time
+		var address *string
+		if rd.Status != nil {
+			lastSync = rd.Status.LastSyncTime
+			nextSync = rd.Status.NextSyncTime
+			if rd.Status.Rsync != nil {
+				address = rd.Status.Rsync.Address
+			}
+		}
+		fmt.Fprintf(w, "replicationdestination/%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			rd.Name, valueOrNone(&destContext), rd.Namespace, copyMethod, scheduleColumn(schedule, rd.Spec.Paused, rd.Annotations),
+			translateTimestampSince(lastSync), translateTimestampUntil(nextSync), valueOrNone(address))
+	}
+	return w.Flush()
+}
+
+func valueOrNone(s *string) string {
+	if s == nil || len(*s) == 0 {
+		return "<none>"
+	}
+	return *s
+}
+
+// translateTimestampSince returns the elapsed time since timestamp in
+// human-readable approximation.
+func translateTimestampSince(timestamp *metav1.Time) string {
+	if timestamp == nil || timestamp.IsZero() {
+		return "<none>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
+
+// translateTimestampUntil returns the time remaining until timestamp in
+// human-readable approximation.
+func translateTimestampUntil(timestamp *metav1.Time) string {
+	if timestamp == nil || timestamp.IsZero() {
+		return "<none>"
+	}
+	remaining := time.Until(timestamp.Time)
+	if remaining <= 0 {
+		return "now"
+	}
+	return "in " + duration.HumanDuration(remaining)
+}
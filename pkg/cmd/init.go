@@ -0,0 +1,442 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// defaultStorageClassAnnotation marks the StorageClass used by PersistentVolumeClaims that do not name one.
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// defaultSnapshotClassAnnotation marks the VolumeSnapshotClass used by VolumeSnapshots that do not name one.
+	defaultSnapshotClassAnnotation = "snapshot.storage.kubernetes.io/is-default-class"
+)
+
+var (
+	scribeInitLong = templates.LongDesc(`
+Write a scribe-config for the replication of a persistent volume, picking its values from the clusters.
+Init lists the contexts of the kubeconfig, then the namespaces and PersistentVolumeClaims of the chosen
+clusters, and asks for each value with a suggested default:
+
+* the source and destination kube contexts and namespaces
+* the PersistentVolumeClaim to replicate. The capacity, access mode and storage class of the destination
+  volume are taken from it
+* the copy methods. Snapshot is suggested when the cluster has a default VolumeSnapshotClass for the
+  storage of the volume, Clone for the source and None for the destination otherwise
+* the service type, LoadBalancer when the contexts differ and ClusterIP otherwise
+* the schedule of the ReplicationSource
+
+An empty answer takes the suggested default. The values are validated, then written to the file of
+--config or SCRIBE_CONFIG, or else to ./scribe-config. The other values of an existing file are kept, and
+with --profile the values are written to that profile.
+`)
+	scribeInitExample = templates.Examples(`
+        # Write ./scribe-config.
+        scribe init
+
+        # Add the profile mysql to ~/.scribe/scribe-config.
+        scribe init --config ~/.scribe/scribe-config --profile mysql
+    `)
+)
+
+type initOptions struct {
+	scribeOptions scribeOptions
+	Filename      string
+	genericclioptions.IOStreams
+}
+
+func NewInitOptions(streams genericclioptions.IOStreams) *initOptions {
+	return &initOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeInit(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewInitOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "init [OPTIONS]",
+		Short:   i18n.T("Write a scribe-config by picking the clusters, namespaces and volume to replicate."),
+		Long:    fmt.Sprintf(scribeInitLong),
+		Example: fmt.Sprintf(scribeInitExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete())
+			kcmdutil.CheckErr(o.Init())
+		},
+	}
+	return cmd
+}
+
+// Complete selects the file to write.
+func (o *initOptions) Complete() error {
+	if strings.Contains(configProfile, ".") {
+		return fmt.Errorf("invalid profile %s, profile names cannot contain '.'", configProfile)
+	}
+	o.Filename, _ = configSource()
+	if len(o.Filename) == 0 {
+		o.Filename = scribeConfig
+	}
+	return nil
+}
+
+// Init asks for the values of the scribe config and writes it.
+func (o *initOptions) Init() error {
+	ctx := context.Background()
+	p := newPrompter(o.In, o.Out)
+	rawConfig, err := genericclioptions.NewConfigFlags(true).ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return err
+	}
+	contexts := []string{}
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	if len(contexts) == 0 {
+		return fmt.Errorf("the kubeconfig has no context, log in to the source and destination clusters first")
+	}
+	sort.Strings(contexts)
+	sourceContext, err := p.choose("Source kube context", contexts, rawConfig.CurrentContext)
+	if err != nil {
+		return err
+	}
+	destContext, err := p.choose("Destination kube context", contexts, sourceContext)
+	if err != nil {
+		return err
+	}
+	o.scribeOptions = scribeOptions{
+		sourceKubeContext: sourceContext,
+		destKubeContext:   destContext,
+		IOStreams:         o.IOStreams,
+	}
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+
+	source := replicationSide{KubeContext: sourceContext}
+	source.Namespace, err = chooseNamespace(ctx, p, "Source namespace", o.scribeOptions.SourceClient, o.scribeOptions.sourceNamespace)
+	if err != nil {
+		return err
+	}
+	pvc, err := choosePVC(ctx, p, o.scribeOptions.SourceClient, source.Namespace, sourceContext)
+	if err != nil {
+		return err
+	}
+	source.PVC = pvc.Name
+	suggested := suggestCopyMethod(ctx, o.scribeOptions.SourceClient, stringValue(pvc.Spec.StorageClassName), "Clone")
+	source.CopyMethod, err = p.choose("Source copy method", []string{"None", "Clone", "Snapshot"}, suggested)
+	if err != nil {
+		return err
+	}
+
+	dest := replicationSide{KubeContext: destContext}
+	dest.Namespace, err = chooseNamespace(ctx, p, "Destination namespace", o.scribeOptions.DestinationClient, o.scribeOptions.destNamespace)
+	if err != nil {
+		return err
+	}
+	if capacity := pvcSize(pvc); !capacity.IsZero() {
+		dest.Capacity = capacity.String()
+	}
+	if len(pvc.Spec.AccessModes) > 0 {
+		dest.AccessMode = string(pvc.Spec.AccessModes[0])
+	}
+	dest.StorageClassName, err = chooseStorageClass(ctx, p, o.scribeOptions.DestinationClient, stringValue(pvc.Spec.StorageClassName))
+	if err != nil {
+		return err
+	}
+	suggested = suggestCopyMethod(ctx, o.scribeOptions.DestinationClient, dest.StorageClassName, "None")
+	dest.CopyMethod, err = p.choose("Destination copy method", []string{"None", "Clone", "Snapshot"}, suggested)
+	if err != nil {
+		return err
+	}
+	suggested = "ClusterIP"
+	if sourceContext != destContext {
+		// the source cluster connects to the destination from outside its cluster
+		suggested = "LoadBalancer"
+	}
+	dest.ServiceType, err = p.choose("Destination service type", []string{"ClusterIP", "LoadBalancer"}, suggested)
+	if err != nil {
+		return err
+	}
+	source.Schedule, err = p.ask("Schedule of the ReplicationSource (cron spec)", "*/3 * * * *", func(schedule string) error {
+		_, err := cron.ParseStandard(schedule)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	pair := &replicationPlanPair{Name: source.PVC, Source: source, Destination: dest}
+	if err := pair.validate(); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+	return o.write(pair.config())
+}
+
+// write sets the values in the scribe config file, or in its profile with --profile, keeping the others.
+func (o *initOptions) write(values map[string]interface{}) error {
+	config := map[string]interface{}{}
+	data, err := ioutil.ReadFile(o.Filename)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return fmt.Errorf("%s: %v", o.Filename, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	target := config
+	if len(configProfile) > 0 {
+		profiles, _ := config[profilesKey].(map[string]interface{})
+		if profiles == nil {
+			profiles = map[string]interface{}{}
+		}
+		profile, _ := profiles[configProfile].(map[string]interface{})
+		if profile == nil {
+			profile = map[string]interface{}{}
+		}
+		profiles[configProfile] = profile
+		config[profilesKey] = profiles
+		target = profile
+	}
+	for key, value := range values {
+		target[key] = value
+	}
+	data, err = yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(o.Filename, data, 0644); err != nil {
+		return err
+	}
+	if len(configProfile) > 0 {
+		klog.V(0).Infof("profile %s written to %s", configProfile, o.Filename)
+	} else {
+		klog.V(0).Infof("scribe config written to %s", o.Filename)
+	}
+	return nil
+}
+
+// chooseNamespace asks for a namespace among the namespaces of the cluster, or by name when they cannot be listed.
+func chooseNamespace(ctx context.Context, p *prompter, question string, c client.Client, defaultNamespace string) (string, error) {
+	namespaces := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaces); err != nil {
+		klog.V(2).Infof("unable to list namespaces: %v", err)
+		return p.ask(question, defaultNamespace, nil)
+	}
+	names := []string{}
+	for _, ns := range namespaces.Items {
+		names = append(names, ns.Name)
+	}
+	return p.choose(question, names, defaultNamespace)
+}
+
+// choosePVC asks for one of the PersistentVolumeClaims of the namespace.
+func choosePVC(ctx context.Context, p *prompter, c client.Client, namespace, kubeContext string) (*corev1.PersistentVolumeClaim, error) {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	if len(pvcs.Items) == 0 {
+		return nil, fmt.Errorf("no PersistentVolumeClaim to replicate in namespace %s of context %s", namespace, kubeContext)
+	}
+	names := []string{}
+	for _, pvc := range pvcs.Items {
+		names = append(names, pvc.Name)
+	}
+	defaultName := ""
+	if len(names) == 1 {
+		defaultName = names[0]
+	}
+	name, err := p.choose("PersistentVolumeClaim to replicate", names, defaultName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if pvc.Name != name {
+			continue
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			klog.Warningf("PersistentVolumeClaim %s is %s, not Bound", pvc.Name, pvc.Status.Phase)
+		}
+		return pvc, nil
+	}
+	return nil, fmt.Errorf("PersistentVolumeClaim %s not found", name)
+}
+
+// chooseStorageClass asks for a StorageClass of the cluster, suggesting the one of the same name as the
+// source volume or else the default StorageClass.
+func chooseStorageClass(ctx context.Context, p *prompter, c client.Client, sourceStorageClass string) (string, error) {
+	storageClasses := &storagev1.StorageClassList{}
+	if err := c.List(ctx, storageClasses); err != nil {
+		klog.V(2).Infof("unable to list storage classes: %v", err)
+		return p.ask("Destination storage class, empty for the default", sourceStorageClass, nil)
+	}
+	if len(storageClasses.Items) == 0 {
+		return "", nil
+	}
+	names := []string{}
+	suggested := ""
+	for _, sc := range storageClasses.Items {
+		names = append(names, sc.Name)
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" && len(suggested) == 0 {
+			suggested = sc.Name
+		}
+	}
+	for _, name := range names {
+		if name == sourceStorageClass {
+			suggested = name
+		}
+	}
+	return p.choose("Destination storage class", names, suggested)
+}
+
+// suggestCopyMethod returns Snapshot if the cluster has a default VolumeSnapshotClass for the driver of the
+// storage class, and fallback otherwise.
+func suggestCopyMethod(ctx context.Context, c client.Client, storageClass, fallback string) string {
+	driver := ""
+	if len(storageClass) > 0 {
+		sc := &storagev1.StorageClass{}
+		if err := c.Get(ctx, types.NamespacedName{Name: storageClass}, sc); err == nil {
+			driver = sc.Provisioner
+		}
+	}
+	vsc, err := defaultVolumeSnapshotClass(ctx, c, driver)
+	if err != nil {
+		klog.V(2).Infof("unable to list volume snapshot classes: %v", err)
+		return fallback
+	}
+	if len(vsc) == 0 {
+		return fallback
+	}
+	return "Snapshot"
+}
+
+// defaultVolumeSnapshotClass returns the name of the default VolumeSnapshotClass of the driver, or of any
+// driver if driver is empty, or an empty name if there is none.
+func defaultVolumeSnapshotClass(ctx context.Context, c client.Client, driver string) (string, error) {
+	vscs, err := listVolumeSnapshotClasses(ctx, c)
+	if meta.IsNoMatchError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, vsc := range vscs {
+		if vsc.GetAnnotations()[defaultSnapshotClassAnnotation] != "true" {
+			continue
+		}
+		if vscDriver, _, _ := unstructured.NestedString(vsc.Object, "driver"); len(driver) == 0 || vscDriver == driver {
+			return vsc.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// prompter asks the questions of an interactive command.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// readAnswer returns the next line of input, trimmed, and io.EOF once there is no more input.
+func (p *prompter) readAnswer() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
+
+// choose asks for one of the choices, by number or by name. An empty answer takes defaultChoice if it is
+// one of the choices.
+func (p *prompter) choose(question string, choices []string, defaultChoice string) (string, error) {
+	validDefault := false
+	for _, choice := range choices {
+		validDefault = validDefault || choice == defaultChoice
+	}
+	for {
+		fmt.Fprintf(p.out, "%s:\n", question)
+		for i, choice := range choices {
+			fmt.Fprintf(p.out, "  %d) %s\n", i+1, choice)
+		}
+		if validDefault {
+			fmt.Fprintf(p.out, "Choice [%s]: ", defaultChoice)
+		} else {
+			fmt.Fprint(p.out, "Choice: ")
+		}
+		answer, err := p.readAnswer()
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if len(answer) == 0 && validDefault {
+			return defaultChoice, nil
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1], nil
+		}
+		for _, choice := range choices {
+			if answer == choice {
+				return choice, nil
+			}
+		}
+		if err == io.EOF {
+			return "", fmt.Errorf("no answer to %q", question)
+		}
+		fmt.Fprintf(p.out, "%q is not one of the choices\n", answer)
+	}
+}
+
+// ask asks for a value checked by validate, if not nil. An empty answer takes defaultValue.
+func (p *prompter) ask(question, defaultValue string, validate func(string) error) (string, error) {
+	for {
+		if len(defaultValue) > 0 {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		answer, err := p.readAnswer()
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if len(answer) == 0 {
+			answer = defaultValue
+		}
+		if validate == nil {
+			return answer, nil
+		}
+		validErr := validate(answer)
+		if validErr == nil {
+			return answer, nil
+		}
+		if err == io.EOF {
+			return "", fmt.Errorf("invalid answer to %q: %v", question, validErr)
+		}
+		fmt.Fprintf(p.out, "invalid answer: %v\n", validErr)
+	}
+}
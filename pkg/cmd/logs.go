@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	scribeLogsLong = templates.LongDesc(`
+Print the logs of the mover Pods of a replication pair. The movers are the Jobs the scribe operator
+runs for the ReplicationSource and the ReplicationDestination during a sync; their Pods are found in the
+cluster of each side, whatever the kube context of the command.
+
+With --side both, the default, each line is prefixed with the side and the name of the Pod. With -f, the
+logs are streamed, and the Pods of the next syncs are followed as they start, until interrupted.
+
+The pair is selected as for status.
+`)
+	scribeLogsExample = templates.Examples(`
+        # Print the logs of the movers of the ReplicationDestination 'dest-destination' and its ReplicationSource.
+        scribe logs dest-destination --dest-namespace dest --source-namespace source
+
+        # Stream the logs of the mover of the destination cluster.
+        scribe logs dest-destination --dest-namespace dest --side destination -f
+    `)
+)
+
+type logsOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	Side          string
+	Follow        bool
+	genericclioptions.IOStreams
+}
+
+func NewLogsOptions(streams genericclioptions.IOStreams) *logsOptions {
+	return &logsOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeLogs(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewLogsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "logs [NAME] [OPTIONS]",
+		Short:   i18n.T("Print the logs of the mover Pods of a replication pair."),
+		Long:    fmt.Sprintf(scribeLogsLong),
+		Example: fmt.Sprintf(scribeLogsExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Logs())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *logsOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&o.Side, "side", "both", "the movers to print the logs of; one of 'source|destination|both'")
+	flags.BoolVarP(&o.Follow, "follow", "f", o.Follow, "stream the logs, following the movers of the next syncs until interrupted.")
+}
+
+func (o *logsOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *logsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// Validate validates logs options.
+func (o *logsOptions) Validate() error {
+	return validateSide(o.Side)
+}
+
+// Logs prints the logs of the mover Pods of the pair, or streams them with --follow.
+func (o *logsOptions) Logs() error {
+	ctx := context.Background()
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	if pair.Source == nil && o.Side == "source" {
+		return fmt.Errorf("no ReplicationSource in namespace %s replicates to ReplicationDestination %s", o.scribeOptions.sourceNamespace, pair.Destination.Name)
+	}
+	sides := []pairSide{}
+	for _, side := range o.scribeOptions.pairSides(pair) {
+		if o.Side == "both" || o.Side == side.name {
+			sides = append(sides, side)
+		}
+	}
+	w := &logWriter{out: o.Out}
+	if o.Follow {
+		ctx, cancel := interruptContext()
+		defer cancel()
+		return o.followLogs(ctx, sides, w)
+	}
+	printed := 0
+	for _, side := range sides {
+		pods, err := side.moverPods(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range pods {
+			if pods[i].Status.Phase == corev1.PodPending {
+				klog.V(0).Infof("mover Pod %s of the %s has not started", pods[i].Name, side.name)
+				continue
+			}
+			if err := streamPodLogs(ctx, side, &pods[i], false, o.prefix(side, &pods[i]), w); err != nil {
+				return err
+			}
+			printed++
+		}
+	}
+	if printed == 0 {
+		return fmt.Errorf("no mover Pod of ReplicationDestination %s has run; the movers run during a sync, pass -f to wait for the next one", pair.Destination.Name)
+	}
+	return nil
+}
+
+// followLogs streams the logs of the mover Pods of the sides, polling for the Pods of the next syncs
+// until the context is done. Errors listing the Pods are logged and the polling goes on.
+func (o *logsOptions) followLogs(ctx context.Context, sides []pairSide, w *logWriter) error {
+	following := map[types.UID]bool{}
+	var wg sync.WaitGroup
+	klog.V(0).Infof("following the logs of the mover Pods, the movers run during a sync")
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		for _, side := range sides {
+			pods, err := side.moverPods(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					klog.Errorf("unable to list the mover Pods of the %s: %v", side.name, err)
+				}
+				continue
+			}
+			for i := range pods {
+				pod := pods[i]
+				if following[pod.UID] || pod.Status.Phase == corev1.PodPending {
+					continue
+				}
+				following[pod.UID] = true
+				wg.Add(1)
+				go func(side pairSide) {
+					defer wg.Done()
+					if err := streamPodLogs(ctx, side, &pod, true, o.prefix(side, &pod), w); err != nil && ctx.Err() == nil {
+						klog.Errorf("unable to stream the logs of Pod %s: %v", pod.Name, err)
+					}
+				}(side)
+			}
+		}
+		return false, nil
+	}, ctx.Done())
+	// the streams end with the context
+	wg.Wait()
+	if err == wait.ErrWaitTimeout {
+		// interrupted
+		return nil
+	}
+	return err
+}
+
+// prefix returns the prefix of the log lines of the Pod, empty unless both sides are printed.
+func (o *logsOptions) prefix(side pairSide, pod *corev1.Pod) string {
+	if o.Side != "both" {
+		return ""
+	}
+	return fmt.Sprintf("[%s/%s] ", side.name, pod.Name)
+}
+
+// streamPodLogs copies the logs of the Pod to w, prefixing each line.
+func streamPodLogs(ctx context.Context, side pairSide, pod *corev1.Pod, follow bool, prefix string, w *logWriter) error {
+	req := side.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: follow})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	r := bufio.NewReader(stream)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			w.writeLine(prefix, line)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// logWriter writes the log lines of several Pods without interleaving them.
+type logWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *logWriter) writeLine(prefix, line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if line[len(line)-1] != '\n' {
+		line += "\n"
+	}
+	fmt.Fprint(w.out, prefix+line)
+}
@@ -1,8 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 )
@@ -23,3 +43,281 @@ type commonOptions struct {
 	port                *int32
 	parameters          map[string]string
 }
+
+// parseAccessMode returns the access modes for one of 'ReadWriteOnce|ReadOnlyMany|ReadWriteMany'.
+func parseAccessMode(accessMode string) ([]corev1.PersistentVolumeAccessMode, error) {
+	switch accessMode {
+	case "ReadWriteOnce":
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, nil
+	case "ReadWriteMany":
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, nil
+	case "ReadOnlyMany":
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}, nil
+	}
+	return nil, fmt.Errorf("unrecognized access mode %s", accessMode)
+}
+
+// parseCopyMethod returns the copy method for one of 'None|Clone|Snapshot'.
+func parseCopyMethod(copyMethod string) (scribev1alpha1.CopyMethodType, error) {
+	switch copyMethod {
+	case "None", "none":
+		return scribev1alpha1.CopyMethodNone, nil
+	case "Clone", "clone":
+		return scribev1alpha1.CopyMethodClone, nil
+	case "Snapshot", "snapshot", "SnapShot":
+		return scribev1alpha1.CopyMethodSnapshot, nil
+	}
+	return "", fmt.Errorf("unrecognized copy method %s", copyMethod)
+}
+
+// parseServiceType returns the service type for one of 'ClusterIP|LoadBalancer'.
+func parseServiceType(serviceType string) (corev1.ServiceType, error) {
+	switch serviceType {
+	case "ClusterIP", "clusterip", "clusterIP":
+		return corev1.ServiceTypeClusterIP, nil
+	case "LoadBalancer", "loadbalancer", "Loadbalancer":
+		return corev1.ServiceTypeLoadBalancer, nil
+	}
+	return "", fmt.Errorf("unrecognized service type %s", serviceType)
+}
+
+// parseProviderParameters returns the parameters of an external provider passed as 'key/value,key1/value1'.
+func parseProviderParameters(parameters string) (map[string]string, error) {
+	params := make(map[string]string)
+	if len(parameters) == 0 {
+		return params, nil
+	}
+	for _, kv := range strings.Split(parameters, ",") {
+		pair := strings.Split(kv, "/")
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("error parsing --provider-parameters %s, must be passed as key/value,key1/value1...", parameters)
+		}
+		params[pair[0]] = pair[1]
+	}
+	return params, nil
+}
+
+// printOptions implements --dry-run and --output for the commands creating or updating scribe objects.
+type printOptions struct {
+	PrintFlags     *genericclioptions.PrintFlags
+	DryRunStrategy kcmdutil.DryRunStrategy
+	printer        printers.ResourcePrinter
+}
+
+// newPrintOptions returns the print options of a command, operation being what the command does to
+// the object, such as "created".
+func newPrintOptions(operation string) printOptions {
+	return printOptions{
+		PrintFlags: genericclioptions.NewPrintFlags(operation).WithTypeSetter(scheme),
+	}
+}
+
+func (o *printOptions) bindFlags(cmd *cobra.Command) {
+	o.PrintFlags.AddFlags(cmd)
+	kcmdutil.AddDryRunFlag(cmd)
+}
+
+// Complete reads the dry-run strategy and sets up the printer. Options built without
+// print flags, as composed by other commands, always create the object.
+func (o *printOptions) Complete(cmd *cobra.Command) error {
+	if o.PrintFlags == nil {
+		return nil
+	}
+	var err error
+	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	kcmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	o.printer, err = o.PrintFlags.ToPrinter()
+	return err
+}
+
+// create creates the object according to the dry-run strategy, then prints it if an output
+// format was requested or logs its creation otherwise.
+func (o *printOptions) create(ctx context.Context, c client.Client, obj runtime.Object, out io.Writer) error {
+	// the kind is cleared when the created object is decoded from the response
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	switch o.DryRunStrategy {
+	case kcmdutil.DryRunClient:
+	case kcmdutil.DryRunServer:
+		if err := c.Create(ctx, obj, client.DryRunAll); err != nil {
+			return err
+		}
+	default:
+		if err := c.Create(ctx, obj); err != nil {
+			return err
+		}
+	}
+	return o.print(obj, kind, "created", out)
+}
+
+// patch applies the changes from base to obj as a merge patch according to the dry-run strategy,
+// then prints the object if an output format was requested or logs its update otherwise.
+func (o *printOptions) patch(ctx context.Context, c client.Client, obj, base runtime.Object, out io.Writer) error {
+	// objects read from the cluster have no kind set
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+	switch o.DryRunStrategy {
+	case kcmdutil.DryRunClient:
+	case kcmdutil.DryRunServer:
+		if err := c.Patch(ctx, obj, client.MergeFrom(base), client.DryRunAll); err != nil {
+			return err
+		}
+	default:
+		if err := c.Patch(ctx, obj, client.MergeFrom(base)); err != nil {
+			return err
+		}
+	}
+	return o.print(obj, gvk.Kind, "configured", out)
+}
+
+// outputRequested returns true if an output format was passed with --output.
+func (o *printOptions) outputRequested() bool {
+	return o.printer != nil && o.PrintFlags.OutputFlagSpecified != nil && o.PrintFlags.OutputFlagSpecified()
+}
+
+func (o *printOptions) print(obj runtime.Object, kind, operation string, out io.Writer) error {
+	if o.outputRequested() {
+		return o.printer.PrintObj(obj, out)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	dryRun := ""
+	switch o.DryRunStrategy {
+	case kcmdutil.DryRunClient:
+		dryRun = " (dry run)"
+	case kcmdutil.DryRunServer:
+		dryRun = " (server dry run)"
+	}
+	klog.V(0).Infof("%s %s %s in namespace %s%s", kind, accessor.GetName(), operation, accessor.GetNamespace(), dryRun)
+	return nil
+}
+
+// validateMover checks the --mover flag and that the rclone flags are set when using rclone.
+func validateMover(mover, rcloneConfig, rcloneConfigSection, rcloneDestPath string) error {
+	switch mover {
+	case "", "rsync":
+	case "rclone":
+		if len(rcloneConfig) == 0 || len(rcloneConfigSection) == 0 || len(rcloneDestPath) == 0 {
+			return fmt.Errorf("must provide --rclone-config, --rclone-config-section and --rclone-dest-path with --mover rclone")
+		}
+	case "restic":
+		// TODO: support restic once the scribe API this CLI is built with has a restic spec
+		return fmt.Errorf("--mover restic is not supported: the ReplicationSource and ReplicationDestination API of scribe this CLI is built with have no restic spec")
+	default:
+		return fmt.Errorf("unrecognized --mover %s; one of 'rsync|rclone'", mover)
+	}
+	return nil
+}
+
+// validateSide checks the --side flag of the commands acting on either or both clusters.
+func validateSide(side string) error {
+	switch side {
+	case "source", "destination", "both":
+		return nil
+	}
+	return fmt.Errorf("unrecognized --side %s; one of 'source|destination|both'", side)
+}
+
+// secretClient is a client and the namespace to create a secret in.
+type secretClient struct {
+	client    client.Client
+	namespace string
+}
+
+// secretClients returns where to create a secret for the side. The destination is skipped
+// with 'both' when it is the same namespace of the same cluster as the source.
+func (o *scribeOptions) secretClients(side string) []secretClient {
+	clients := []secretClient{}
+	if side != "destination" {
+		clients = append(clients, secretClient{client: o.SourceClient, namespace: o.sourceNamespace})
+	}
+	sameNamespace := o.sourceKubeContext == o.destKubeContext &&
+		o.sourceKubeClusterName == o.destKubeClusterName &&
+		o.sourceNamespace == o.destNamespace
+	if side == "destination" || (side == "both" && !sameNamespace) {
+		clients = append(clients, secretClient{client: o.DestinationClient, namespace: o.destNamespace})
+	}
+	return clients
+}
+
+// applySecret creates the secret with the data in the namespace, or updates the data keys of an existing secret.
+func applySecret(ctx context.Context, c client.Client, namespace, name string, data map[string][]byte) error {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret)
+	switch {
+	case kerrors.IsNotFound(err):
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: data,
+		}
+		if err := c.Create(ctx, secret); err != nil {
+			return err
+		}
+		klog.V(0).Infof("secret %s created in namespace %s", name, namespace)
+		return nil
+	case err != nil:
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	for k, v := range data {
+		secret.Data[k] = v
+	}
+	if err := c.Update(ctx, secret); err != nil {
+		return err
+	}
+	klog.V(0).Infof("secret %s updated in namespace %s", name, namespace)
+	return nil
+}
+
+// cleanObject returns the object as a map without its status and the metadata set by the API server,
+// to compare or print the fields set by users.
+func cleanObject(obj runtime.Object) (map[string]interface{}, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	delete(u, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(u, "metadata", field)
+	}
+	return u, nil
+}
+
+// objectDiff returns a unified diff of the YAML of two versions of the object, empty if they are the same.
+// A nil version, such as an object that does not exist yet, is empty.
+func objectDiff(name, fromLabel, toLabel string, from, to runtime.Object) (string, error) {
+	var texts [2]string
+	for i, obj := range []runtime.Object{from, to} {
+		if obj == nil {
+			continue
+		}
+		clean, err := cleanObject(obj)
+		if err != nil {
+			return "", err
+		}
+		data, err := yaml.Marshal(clean)
+		if err != nil {
+			return "", err
+		}
+		texts[i] = string(data)
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(texts[0]),
+		B:        difflib.SplitLines(texts[1]),
+		FromFile: name + " (" + fromLabel + ")",
+		ToFile:   name + " (" + toLabel + ")",
+		Context:  3,
+	})
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// pollInterval is how often the status of scribe objects is checked while waiting on them.
+const pollInterval = 2 * time.Second
+
+// interruptContext returns a context that is cancelled on SIGINT or SIGTERM, for the commands that
+// follow a pair until interrupted.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(interrupt)
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// replicationPair is a ReplicationDestination and the ReplicationSource that replicates into it.
+// Source is nil when no matching ReplicationSource was found.
+type replicationPair struct {
+	Destination *scribev1alpha1.ReplicationDestination
+	Source      *scribev1alpha1.ReplicationSource
+}
+
+// getReplicationPair looks up the ReplicationDestination destName in the destination namespace
+// and its ReplicationSource in the source namespace. When sourceName is empty, the ReplicationSource
+// is the one that connects to the address or uses the SSH keys published by the ReplicationDestination.
+func (o *scribeOptions) getReplicationPair(ctx context.Context, destName, sourceName string) (*replicationPair, error) {
+	rd := &scribev1alpha1.ReplicationDestination{}
+	nsName := types.NamespacedName{
+		Namespace: o.destNamespace,
+		Name:      destName,
+	}
+	if err := o.DestinationClient.Get(ctx, nsName, rd); err != nil {
+		return nil, err
+	}
+	pair := &replicationPair{Destination: rd}
+	if len(sourceName) > 0 {
+		rs := &scribev1alpha1.ReplicationSource{}
+		nsName := types.NamespacedName{
+			Namespace: o.sourceNamespace,
+			Name:      sourceName,
+		}
+		if err := o.SourceClient.Get(ctx, nsName, rs); err != nil {
+			return nil, err
+		}
+		pair.Source = rs
+		return pair, nil
+	}
+	repSources := &scribev1alpha1.ReplicationSourceList{}
+	if err := o.SourceClient.List(ctx, repSources, client.InNamespace(o.sourceNamespace)); err != nil {
+		return nil, err
+	}
+	for i := range repSources.Items {
+		if sourceMatchesDestination(&repSources.Items[i], rd) {
+			pair.Source = &repSources.Items[i]
+			break
+		}
+	}
+	return pair, nil
+}
+
+// sourceMatchesDestination returns true if the ReplicationSource replicates to the ReplicationDestination.
+func sourceMatchesDestination(rs *scribev1alpha1.ReplicationSource, rd *scribev1alpha1.ReplicationDestination) bool {
+	switch {
+	case rs.Spec.Rsync != nil && rd.Spec.Rsync != nil:
+		if rd.Status == nil || rd.Status.Rsync == nil {
+			return false
+		}
+		if stringPtrsEqual(rs.Spec.Rsync.Address, rd.Status.Rsync.Address) {
+			return true
+		}
+		return stringPtrsEqual(rs.Spec.Rsync.SSHKeys, rd.Status.Rsync.SSHKeys)
+	case rs.Spec.Rclone != nil && rd.Spec.Rclone != nil:
+		return stringPtrsEqual(rs.Spec.Rclone.RcloneDestPath, rd.Spec.Rclone.RcloneDestPath) &&
+			stringPtrsEqual(rs.Spec.Rclone.RcloneConfigSection, rd.Spec.Rclone.RcloneConfigSection)
+	}
+	return false
+}
+
+// stringPtrsEqual returns true if both strings are set and equal.
+func stringPtrsEqual(a, b *string) bool {
+	return a != nil && b != nil && len(*a) > 0 && *a == *b
+}
+
+// pairOptions selects a replication pair by the name of its ReplicationDestination, and
+// optionally the name of its ReplicationSource.
+type pairOptions struct {
+	DestName   string
+	SourceName string
+}
+
+func (o *pairOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.DestName, "dest-name", o.DestName, "name of the ReplicationDestination of the pair, if not passed as an argument. (default '<dest-namespace>-destination')")
+	flags.StringVar(&o.SourceName, "source-name", o.SourceName, "name of the ReplicationSource of the pair. If not set, the ReplicationSource that replicates to the ReplicationDestination is used.")
+}
+
+// Complete infers the ReplicationDestination name from the arguments or the defaults of new-destination.
+func (o *pairOptions) Complete(cmd *cobra.Command, args []string, destNamespace string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.DestName = args[0]
+	default:
+		return kcmdutil.UsageErrorf(cmd, "expected the name of one ReplicationDestination, got %d arguments", len(args))
+	}
+	if len(o.DestName) == 0 {
+		o.DestName = destNamespace + "-destination"
+	}
+	return nil
+}
+
+// waitForDestination polls the ReplicationDestination until ready returns true or the timeout expires.
+func waitForDestination(ctx context.Context, c client.Client, nsName types.NamespacedName, timeout time.Duration, ready func(*scribev1alpha1.ReplicationDestination) bool) (*scribev1alpha1.ReplicationDestination, error) {
+	rd := &scribev1alpha1.ReplicationDestination{}
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		if err := c.Get(ctx, nsName, rd); err != nil {
+			return false, err
+		}
+		return ready(rd), nil
+	})
+	return rd, err
+}
+
+// waitForSource polls the ReplicationSource until ready returns true or the timeout expires.
+func waitForSource(ctx context.Context, c client.Client, nsName types.NamespacedName, timeout time.Duration, ready func(*scribev1alpha1.ReplicationSource) bool) (*scribev1alpha1.ReplicationSource, error) {
+	rs := &scribev1alpha1.ReplicationSource{}
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		if err := c.Get(ctx, nsName, rs); err != nil {
+			return false, err
+		}
+		return ready(rs), nil
+	})
+	return rs, err
+}
+
+// pollSource polls the ReplicationSource until ready returns true or the context is done.
+func pollSource(ctx context.Context, c client.Client, nsName types.NamespacedName, ready func(*scribev1alpha1.ReplicationSource) bool) (*scribev1alpha1.ReplicationSource, error) {
+	rs := &scribev1alpha1.ReplicationSource{}
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		if err := c.Get(ctx, nsName, rs); err != nil {
+			return false, err
+		}
+		return ready(rs), nil
+	}, ctx.Done())
+	return rs, err
+}
+
+// pollDestination polls the ReplicationDestination until ready returns true or the context is done.
+func pollDestination(ctx context.Context, c client.Client, nsName types.NamespacedName, ready func(*scribev1alpha1.ReplicationDestination) bool) (*scribev1alpha1.ReplicationDestination, error) {
+	rd := &scribev1alpha1.ReplicationDestination{}
+	err := wait.PollImmediateUntil(pollInterval, func() (bool, error) {
+		if err := c.Get(ctx, nsName, rd); err != nil {
+			return false, err
+		}
+		return ready(rd), nil
+	}, ctx.Done())
+	return rd, err
+}
+
+// destinationHasAddress returns true once the ReplicationDestination publishes its rsync address.
+func destinationHasAddress(rd *scribev1alpha1.ReplicationDestination) bool {
+	return rd.Status != nil && rd.Status.Rsync != nil && rd.Status.Rsync.Address != nil && len(*rd.Status.Rsync.Address) > 0
+}
+
+// destinationSSHKeysSecret returns the name of the SSH keys secret a ReplicationSource uses to connect
+// to the ReplicationDestination, as copied by sync-ssh-secret.
+func destinationSSHKeysSecret(rd *scribev1alpha1.ReplicationDestination) string {
+	if rd.Status != nil && rd.Status.Rsync != nil && rd.Status.Rsync.SSHKeys != nil {
+		return *rd.Status.Rsync.SSHKeys
+	}
+	return "scribe-rsync-dest-src-" + rd.Name
+}
+
+// pairSide is the ReplicationSource or the ReplicationDestination of a pair with the client of its cluster.
+type pairSide struct {
+	name        string
+	kind        string
+	object      metav1.Object
+	client      client.Client
+	clientset   kubernetes.Interface
+	kubeContext string
+}
+
+// pairSides returns the sides of the pair, the source first. The source is missing when the pair has
+// no ReplicationSource.
+func (o *scribeOptions) pairSides(pair *replicationPair) []pairSide {
+	sides := []pairSide{}
+	if pair.Source != nil {
+		sides = append(sides, pairSide{name: "source", kind: "ReplicationSource", object: pair.Source, client: o.SourceClient, clientset: o.SourceClientset, kubeContext: o.sourceKubeContext})
+	}
+	return append(sides, pairSide{name: "destination", kind: "ReplicationDestination", object: pair.Destination, client: o.DestinationClient, clientset: o.DestinationClientset, kubeContext: o.destKubeContext})
+}
+
+// ownedBy returns true if owner is the controller of obj.
+func ownedBy(obj, owner metav1.Object) bool {
+	ref := metav1.GetControllerOf(obj)
+	return ref != nil && ref.UID == owner.GetUID()
+}
+
+// moverJobs returns the mover Jobs the operator created for the side.
+func (s *pairSide) moverJobs(ctx context.Context) ([]batchv1.Job, error) {
+	jobs := &batchv1.JobList{}
+	if err := s.client.List(ctx, jobs, client.InNamespace(s.object.GetNamespace())); err != nil {
+		return nil, err
+	}
+	owned := []batchv1.Job{}
+	for _, job := range jobs.Items {
+		if ownedBy(&job, s.object) {
+			owned = append(owned, job)
+		}
+	}
+	return owned, nil
+}
+
+// moverPods returns the Pods of the mover Jobs of the side.
+func (s *pairSide) moverPods(ctx context.Context) ([]corev1.Pod, error) {
+	jobs, err := s.moverJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owned := []corev1.Pod{}
+	for i := range jobs {
+		pods := &corev1.PodList{}
+		if err := s.client.List(ctx, pods, client.InNamespace(jobs[i].Namespace), client.MatchingLabels{"job-name": jobs[i].Name}); err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			if ownedBy(&pod, &jobs[i]) {
+				owned = append(owned, pod)
+			}
+		}
+	}
+	return owned, nil
+}
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+// pausedScheduleAnnotation records the schedule of a paused ReplicationSource or ReplicationDestination,
+// empty when it synced continuously, so that resume can put it back.
+const pausedScheduleAnnotation = "scribe.backube/paused-schedule"
+
+var (
+	scribePauseLong = templates.LongDesc(`
+Pause the replication of a pair without deleting anything. The ReplicationSource and the
+ReplicationDestination are marked paused and their schedules are removed. Each schedule is recorded in
+the annotation scribe.backube/paused-schedule, so that resume puts back the exact same schedule.
+`)
+	scribePauseExample = templates.Examples(`
+        # Pause the replication to the ReplicationDestination 'dest-destination' during a maintenance window.
+        scribe pause dest-destination --dest-namespace dest --source-namespace source
+    `)
+	scribeResumeLong = templates.LongDesc(`
+Resume the replication of a pair paused with pause. The schedules recorded when the pair was paused are
+put back on the ReplicationSource and the ReplicationDestination, and both are unpaused.
+`)
+	scribeResumeExample = templates.Examples(`
+        # Resume the replication to the ReplicationDestination 'dest-destination' after a maintenance window.
+        scribe resume dest-destination --dest-namespace dest --source-namespace source
+    `)
+)
+
+type pauseOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	genericclioptions.IOStreams
+}
+
+func NewPauseOptions(streams genericclioptions.IOStreams) *pauseOptions {
+	return &pauseOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribePause(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewPauseOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "pause [NAME] [OPTIONS]",
+		Short:   i18n.T("Pause the replication of a pair, keeping its schedule to resume it."),
+		Long:    fmt.Sprintf(scribePauseLong),
+		Example: fmt.Sprintf(scribePauseExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Pause())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func NewCmdScribeResume(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewPauseOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "resume [NAME] [OPTIONS]",
+		Short:   i18n.T("Resume the replication of a paused pair with its original schedule."),
+		Long:    fmt.Sprintf(scribeResumeLong),
+		Example: fmt.Sprintf(scribeResumeExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Resume())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *pauseOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+}
+
+func (o *pauseOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *pauseOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// getPair returns the pair to pause or resume, which must have a ReplicationSource.
+func (o *pauseOptions) getPair(ctx context.Context) (*replicationPair, error) {
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return nil, err
+	}
+	if pair.Source == nil {
+		return nil, fmt.Errorf("no ReplicationSource in namespace %s replicates to ReplicationDestination %s, pass --source-name", o.scribeOptions.sourceNamespace, pair.Destination.Name)
+	}
+	return pair, nil
+}
+
+// Pause pauses the ReplicationSource first, so that it stops sending data, then the ReplicationDestination.
+func (o *pauseOptions) Pause() error {
+	ctx := context.Background()
+	pair, err := o.getPair(ctx)
+	if err != nil {
+		return err
+	}
+	if err := pauseSource(ctx, o.scribeOptions.SourceClient, pair.Source); err != nil {
+		return err
+	}
+	return pauseDestination(ctx, o.scribeOptions.DestinationClient, pair.Destination)
+}
+
+// Resume resumes the ReplicationDestination first, so that it is ready to receive data, then the ReplicationSource.
+func (o *pauseOptions) Resume() error {
+	ctx := context.Background()
+	pair, err := o.getPair(ctx)
+	if err != nil {
+		return err
+	}
+	if err := resumeDestination(ctx, o.scribeOptions.DestinationClient, pair.Destination); err != nil {
+		return err
+	}
+	return resumeSource(ctx, o.scribeOptions.SourceClient, pair.Source)
+}
+
+func pauseSource(ctx context.Context, c client.Client, rs *scribev1alpha1.ReplicationSource) error {
+	if _, ok := rs.Annotations[pausedScheduleAnnotation]; ok && rs.Spec.Paused {
+		klog.V(0).Infof("ReplicationSource %s in namespace %s is already paused", rs.Name, rs.Namespace)
+		return nil
+	}
+	var schedule *string
+	if rs.Spec.Trigger != nil {
+		schedule = rs.Spec.Trigger.Schedule
+	}
+	patched := rs.DeepCopy()
+	patched.Annotations = recordSchedule(patched.Annotations, schedule)
+	patched.Spec.Trigger = nil
+	patched.Spec.Paused = true
+	if err := c.Patch(ctx, patched, client.MergeFrom(rs)); err != nil {
+		return err
+	}
+	klog.V(0).Infof("ReplicationSource %s in namespace %s paused", rs.Name, rs.Namespace)
+	return nil
+}
+
+func pauseDestination(ctx context.Context, c client.Client, rd *scribev1alpha1.ReplicationDestination) error {
+	if _, ok := rd.Annotations[pausedScheduleAnnotation]; ok && rd.Spec.Paused {
+		klog.V(0).Infof("ReplicationDestination %s in namespace %s is already paused", rd.Name, rd.Namespace)
+		return nil
+	}
+	var schedule *string
+	if rd.Spec.Trigger != nil {
+		schedule = rd.Spec.Trigger.Schedule
+	}
+	patched := rd.DeepCopy()
+	patched.Annotations = recordSchedule(patched.Annotations, schedule)
+	patched.Spec.Trigger = nil
+	patched.Spec.Paused = true
+	if err := c.Patch(ctx, patched, client.MergeFrom(rd)); err != nil {
+		return err
+	}
+	klog.V(0).Infof("ReplicationDestination %s in namespace %s paused", rd.Name, rd.Namespace)
+	return nil
+}
+
+func resumeSource(ctx context.Context, c client.Client, rs *scribev1alpha1.ReplicationSource) error {
+	schedule, recorded := rs.Annotations[pausedScheduleAnnotation]
+	if !recorded && !rs.Spec.Paused {
+		klog.V(0).Infof("ReplicationSource %s in namespace %s is not paused", rs.Name, rs.Namespace)
+		return nil
+	}
+	patched := rs.DeepCopy()
+	if recorded {
+		patched.Spec.Trigger = nil
+		if len(schedule) > 0 {
+			patched.Spec.Trigger = &scribev1alpha1.ReplicationSourceTriggerSpec{Schedule: &schedule}
+		}
+		delete(patched.Annotations, pausedScheduleAnnotation)
+	}
+	patched.Spec.Paused = false
+	if err := c.Patch(ctx, patched, client.MergeFrom(rs)); err != nil {
+		return err
+	}
+	klog.V(0).Infof("ReplicationSource %s in namespace %s resumed", rs.Name, rs.Namespace)
+	return nil
+}
+
+func resumeDestination(ctx context.Context, c client.Client, rd *scribev1alpha1.ReplicationDestination) error {
+	schedule, recorded := rd.Annotations[pausedScheduleAnnotation]
+	if !recorded && !rd.Spec.Paused {
+		klog.V(0).Infof("ReplicationDestination %s in namespace %s is not paused", rd.Name, rd.Namespace)
+		return nil
+	}
+	patched := rd.DeepCopy()
+	if recorded {
+		patched.Spec.Trigger = nil
+		if len(schedule) > 0 {
+			patched.Spec.Trigger = &scribev1alpha1.ReplicationDestinationTriggerSpec{Schedule: &schedule}
+		}
+		delete(patched.Annotations, pausedScheduleAnnotation)
+	}
+	patched.Spec.Paused = false
+	if err := c.Patch(ctx, patched, client.MergeFrom(rd)); err != nil {
+		return err
+	}
+	klog.V(0).Infof("ReplicationDestination %s in namespace %s resumed", rd.Name, rd.Namespace)
+	return nil
+}
+
+// recordSchedule records the schedule in the annotations, unless one was recorded by an earlier pause
+// that was not resumed.
+func recordSchedule(annotations map[string]string, schedule *string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if _, ok := annotations[pausedScheduleAnnotation]; ok {
+		return annotations
+	}
+	annotations[pausedScheduleAnnotation] = ""
+	if schedule != nil {
+		annotations[pausedScheduleAnnotation] = *schedule
+	}
+	return annotations
+}
+
+// scheduleColumn returns the schedule to print for an object, marking it when paused.
+func scheduleColumn(schedule *string, paused bool, annotations map[string]string) string {
+	if !paused {
+		return valueOrNone(schedule)
+	}
+	if recorded, ok := annotations[pausedScheduleAnnotation]; ok {
+		schedule = &recorded
+	}
+	return valueOrNone(schedule) + " (paused)"
+}
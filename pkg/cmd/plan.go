@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	replicationPlanAPIVersion = "scribectl.backube/v1alpha1"
+	replicationPlanKind       = "ReplicationPlan"
+)
+
+// replicationPlan describes replication pairs to set up with apply and compare with diff.
+type replicationPlan struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Pairs      []replicationPlanPair `json:"pairs"`
+}
+
+// replicationPlanPair is a ReplicationDestination and the ReplicationSource replicating to it.
+type replicationPlanPair struct {
+	// Name identifies the pair in the output of the commands.
+	Name string `json:"name"`
+	// Mover is one of 'rsync|rclone', rsync if not set.
+	Mover       string             `json:"mover,omitempty"`
+	Rclone      *replicationRclone `json:"rclone,omitempty"`
+	Source      replicationSide    `json:"source"`
+	Destination replicationSide    `json:"destination"`
+}
+
+// replicationRclone is the rclone configuration shared by both sides of a pair.
+type replicationRclone struct {
+	Config        string `json:"config"`
+	ConfigSection string `json:"configSection"`
+	DestPath      string `json:"destPath"`
+}
+
+// replicationSide describes where the ReplicationSource or ReplicationDestination of a pair is and its spec,
+// with the values of the flags of new-source and new-destination. The kube context, cluster and namespace
+// default to the flags of the command. The schedule has no default, unlike --source-cron-spec: a side
+// without a schedule syncs continuously.
+type replicationSide struct {
+	KubeContext             string `json:"kubeContext,omitempty"`
+	ClusterName             string `json:"clusterName,omitempty"`
+	Namespace               string `json:"namespace,omitempty"`
+	Name                    string `json:"name,omitempty"`
+	PVC                     string `json:"pvc,omitempty"`
+	CopyMethod              string `json:"copyMethod"`
+	Capacity                string `json:"capacity,omitempty"`
+	AccessMode              string `json:"accessMode,omitempty"`
+	StorageClassName        string `json:"storageClassName,omitempty"`
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
+	Schedule                string `json:"schedule,omitempty"`
+	Address                 string `json:"address,omitempty"`
+	Port                    int32  `json:"port,omitempty"`
+	Path                    string `json:"path,omitempty"`
+	SSHUser                 string `json:"sshUser,omitempty"`
+	SSHKeysSecret           string `json:"sshKeysSecret,omitempty"`
+	ServiceType             string `json:"serviceType,omitempty"`
+}
+
+// loadReplicationPlan reads and validates the plan in the file, or in stdin for "-".
+func loadReplicationPlan(filename string) (*replicationPlan, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan := &replicationPlan{}
+	if err := yaml.UnmarshalStrict(data, plan); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if err := plan.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return plan, nil
+}
+
+func (p *replicationPlan) validate() error {
+	if p.APIVersion != replicationPlanAPIVersion || p.Kind != replicationPlanKind {
+		return fmt.Errorf("expected apiVersion %s and kind %s, got %s %s", replicationPlanAPIVersion, replicationPlanKind, p.APIVersion, p.Kind)
+	}
+	names := map[string]bool{}
+	for i := range p.Pairs {
+		pair := &p.Pairs[i]
+		if len(pair.Name) == 0 {
+			return fmt.Errorf("pair %d: must have a name", i)
+		}
+		if names[pair.Name] {
+			return fmt.Errorf("pair %s: the name is used by another pair", pair.Name)
+		}
+		names[pair.Name] = true
+		if err := pair.validate(); err != nil {
+			return fmt.Errorf("pair %s: %v", pair.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *replicationPlanPair) validate() error {
+	rclone := replicationRclone{}
+	if p.Rclone != nil {
+		rclone = *p.Rclone
+	}
+	if err := validateMover(p.Mover, rclone.Config, rclone.ConfigSection, rclone.DestPath); err != nil {
+		return err
+	}
+	if len(p.Source.PVC) == 0 {
+		return fmt.Errorf("source: must have a pvc, the PersistentVolumeClaim to replicate")
+	}
+	if len(p.Destination.PVC) == 0 && (len(p.Destination.Capacity) == 0 || len(p.Destination.AccessMode) == 0) {
+		return fmt.Errorf("destination: must either have a capacity and an accessMode or a pvc")
+	}
+	for side, s := range map[string]replicationSide{"source": p.Source, "destination": p.Destination} {
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("%s: %v", side, err)
+		}
+	}
+	return nil
+}
+
+func (s *replicationSide) validate() error {
+	if _, err := parseCopyMethod(s.CopyMethod); err != nil {
+		return fmt.Errorf("copyMethod must be one of 'None|Clone|Snapshot', got %q", s.CopyMethod)
+	}
+	if len(s.Capacity) > 0 {
+		if _, err := resource.ParseQuantity(s.Capacity); err != nil {
+			return fmt.Errorf("invalid capacity %s: %v", s.Capacity, err)
+		}
+	}
+	if len(s.AccessMode) > 0 {
+		if _, err := parseAccessMode(s.AccessMode); err != nil {
+			return fmt.Errorf("accessMode must be one of 'ReadWriteOnce|ReadOnlyMany|ReadWriteMany', got %q", s.AccessMode)
+		}
+	}
+	if len(s.ServiceType) > 0 {
+		if _, err := parseServiceType(s.ServiceType); err != nil {
+			return fmt.Errorf("serviceType must be one of 'ClusterIP|LoadBalancer', got %q", s.ServiceType)
+		}
+	}
+	if len(s.Schedule) > 0 {
+		if _, err := cron.ParseStandard(s.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule %s: %v", s.Schedule, err)
+		}
+	}
+	return nil
+}
+
+// scribeOptions returns the options selecting the clusters and namespaces of the pair, defaulting to
+// the flags of the command. The options still need to be completed.
+func (p *replicationPlanPair) scribeOptions(defaults scribeOptions) scribeOptions {
+	return scribeOptions{
+		destKubeContext:       valueOrDefault(p.Destination.KubeContext, defaults.destKubeContext),
+		sourceKubeContext:     valueOrDefault(p.Source.KubeContext, defaults.sourceKubeContext),
+		destKubeClusterName:   valueOrDefault(p.Destination.ClusterName, defaults.destKubeClusterName),
+		sourceKubeClusterName: valueOrDefault(p.Source.ClusterName, defaults.sourceKubeClusterName),
+		destNamespace:         valueOrDefault(p.Destination.Namespace, defaults.destNamespace),
+		sourceNamespace:       valueOrDefault(p.Source.Namespace, defaults.sourceNamespace),
+		IOStreams:             defaults.IOStreams,
+	}
+}
+
+// destinationOptions returns the options of new-destination describing the ReplicationDestination of the pair,
+// in the clusters of the completed scribe options.
+func (p *replicationPlanPair) destinationOptions(so scribeOptions, streams genericclioptions.IOStreams) *destinationOptions {
+	d := p.Destination
+	o := &destinationOptions{
+		scribeOptions:               so,
+		DestCopyMethod:              d.CopyMethod,
+		DestCapacity:                d.Capacity,
+		DestStorageClassName:        d.StorageClassName,
+		DestAccessMode:              d.AccessMode,
+		Address:                     d.Address,
+		DestVolumeSnapshotClassName: d.VolumeSnapshotClassName,
+		DestPVC:                     d.PVC,
+		DestSchedule:                d.Schedule,
+		SSHUser:                     d.SSHUser,
+		DestName:                    valueOrDefault(d.Name, so.destNamespace+"-destination"),
+		DestNamespace:               so.destNamespace,
+		DestServiceType:             d.ServiceType,
+		Port:                        d.Port,
+		Path:                        d.Path,
+		Mover:                       p.Mover,
+		IOStreams:                   streams,
+	}
+	o.sshKeysSecretOptions.SSHKeysSecret = d.SSHKeysSecret
+	if p.Rclone != nil {
+		o.RcloneConfig = p.Rclone.Config
+		o.RcloneConfigSection = p.Rclone.ConfigSection
+		o.RcloneDestPath = p.Rclone.DestPath
+	}
+	return o
+}
+
+// sourceOptions returns the options of new-source describing the ReplicationSource of the pair, in the
+// clusters of the completed scribe options.
+func (p *replicationPlanPair) sourceOptions(so scribeOptions, streams genericclioptions.IOStreams) *sourceOptions {
+	s := p.Source
+	o := &sourceOptions{
+		scribeOptions:                 so,
+		SourceCopyMethod:              s.CopyMethod,
+		SourceCapacity:                s.Capacity,
+		SourceStorageClassName:        s.StorageClassName,
+		SourceAccessMode:              s.AccessMode,
+		Address:                       s.Address,
+		SourceVolumeSnapshotClassName: s.VolumeSnapshotClassName,
+		SourcePVC:                     s.PVC,
+		SourceSchedule:                s.Schedule,
+		SSHUser:                       s.SSHUser,
+		SourceName:                    valueOrDefault(s.Name, so.sourceNamespace+"-source"),
+		SourceNamespace:               so.sourceNamespace,
+		SourceServiceType:             s.ServiceType,
+		Port:                          s.Port,
+		Path:                          s.Path,
+		Mover:                         p.Mover,
+		IOStreams:                     streams,
+	}
+	o.sshKeysSecretOptions.SSHKeysSecret = s.SSHKeysSecret
+	if p.Rclone != nil {
+		o.RcloneConfig = p.Rclone.Config
+		o.RcloneConfigSection = p.Rclone.ConfigSection
+		o.RcloneDestPath = p.Rclone.DestPath
+	}
+	return o
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// mergePlanned applies the spec of the planned object to the live object as a merge patch and decodes
+// the result in into. Fields the plan does not set are left as they are, including the defaulted fields
+// the planned object only has because new-source and new-destination set a default. The schedule of a
+// paused object is recorded to be put back by resume instead of being applied.
+func mergePlanned(live, planned, into runtime.Object, defaulted [][]string) error {
+	plannedMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(planned)
+	if err != nil {
+		return err
+	}
+	patch := map[string]interface{}{"spec": plannedMap["spec"]}
+	for _, fields := range defaulted {
+		unstructured.RemoveNestedField(patch, fields...)
+	}
+	accessor, err := meta.Accessor(live)
+	if err != nil {
+		return err
+	}
+	if _, paused := accessor.GetAnnotations()[pausedScheduleAnnotation]; paused {
+		schedule, found, err := unstructured.NestedString(plannedMap, "spec", "trigger", "schedule")
+		if err != nil {
+			return err
+		}
+		unstructured.RemoveNestedField(patch, "spec", "trigger")
+		if found {
+			patch["metadata"] = map[string]interface{}{
+				"annotations": map[string]interface{}{pausedScheduleAnnotation: schedule},
+			}
+		}
+	}
+	liveJSON, err := json.Marshal(live)
+	if err != nil {
+		return err
+	}
+	patchJSON, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	merged, err := jsonpatch.MergePatch(liveJSON, patchJSON)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, into)
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+const testPlan = `apiVersion: scribectl.backube/v1alpha1
+kind: ReplicationPlan
+pairs:
+- name: mysql
+  source:
+    namespace: db
+    pvc: mysql-pv-claim
+    copyMethod: Snapshot
+    schedule: "*/3 * * * *"
+    address: 10.0.0.1
+    sshKeysSecret: scribe-rsync-dest-src-db-copy-destination
+  destination:
+    namespace: db-copy
+    copyMethod: Snapshot
+    capacity: 2Gi
+    accessMode: ReadWriteOnce
+`
+
+// plannedSource returns the ReplicationSource of the first pair of the plan, as apply plans it, with its
+// defaulted fields.
+func plannedSource(t *testing.T, plan string) (*scribev1alpha1.ReplicationSource, [][]string) {
+	p := loadTestPlan(t, plan)
+	pair := &p.Pairs[0]
+	s := pair.sourceOptions(pair.scribeOptions(scribeOptions{}), genericclioptions.IOStreams{})
+	rs, err := s.newReplicationSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rs, s.defaultedFields()
+}
+
+// plannedDestination returns the ReplicationDestination of the first pair of the plan, as apply plans it,
+// with its defaulted fields.
+func plannedDestination(t *testing.T, plan string) (*scribev1alpha1.ReplicationDestination, [][]string) {
+	p := loadTestPlan(t, plan)
+	pair := &p.Pairs[0]
+	d := pair.destinationOptions(pair.scribeOptions(scribeOptions{}), genericclioptions.IOStreams{})
+	rd, err := d.newReplicationDestination()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rd, d.defaultedFields()
+}
+
+func loadTestPlan(t *testing.T, plan string) *replicationPlan {
+	p, err := loadReplicationPlan(writeTestFile(t, plan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// writeTestFile writes the data to a temporary file removed at the end of the test and returns its name.
+func writeTestFile(t *testing.T, data string) string {
+	f, err := ioutil.TempFile("", "scribe-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Remove(f.Name()) })
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestMergePlanned(t *testing.T) {
+	schedule := func(s string) *scribev1alpha1.ReplicationSourceTriggerSpec {
+		return &scribev1alpha1.ReplicationSourceTriggerSpec{Schedule: &s}
+	}
+	tests := []struct {
+		name string
+		// live changes the live object, which starts as the planned object as read from the cluster.
+		live func(rs *scribev1alpha1.ReplicationSource)
+		// plan changes the plan of the pair.
+		plan       func(plan string) string
+		wantDiff   bool
+		wantPaused bool
+		// wantSchedule is the schedule of the merged object, empty for no trigger.
+		wantSchedule string
+		// wantRecorded is the schedule recorded by pause in the merged object, if paused.
+		wantRecorded string
+	}{
+		{
+			name:         "live object equals plan",
+			wantSchedule: "*/3 * * * *",
+		},
+		{
+			name: "fields the plan does not set are kept",
+			live: func(rs *scribev1alpha1.ReplicationSource) {
+				rs.Labels = map[string]string{"app": "mysql"}
+				path := "/data"
+				rs.Spec.Rsync.Path = &path
+			},
+			wantSchedule: "*/3 * * * *",
+		},
+		{
+			name: "service type defaulted by new-source is kept",
+			live: func(rs *scribev1alpha1.ReplicationSource) {
+				serviceType := corev1.ServiceTypeLoadBalancer
+				rs.Spec.Rsync.ServiceType = &serviceType
+			},
+			wantSchedule: "*/3 * * * *",
+		},
+		{
+			name: "service type set by the plan",
+			live: func(rs *scribev1alpha1.ReplicationSource) {
+				serviceType := corev1.ServiceTypeLoadBalancer
+				rs.Spec.Rsync.ServiceType = &serviceType
+			},
+			plan: func(plan string) string {
+				return strings.Replace(plan, "    address: 10.0.0.1\n", "    address: 10.0.0.1\n    serviceType: ClusterIP\n", 1)
+			},
+			wantDiff:     true,
+			wantSchedule: "*/3 * * * *",
+		},
+		{
+			name:         "drifted schedule",
+			live:         func(rs *scribev1alpha1.ReplicationSource) { rs.Spec.Trigger = schedule("0 * * * *") },
+			wantDiff:     true,
+			wantSchedule: "*/3 * * * *",
+		},
+		{
+			name: "drifted copy method",
+			live: func(rs *scribev1alpha1.ReplicationSource) {
+				rs.Spec.Rsync.CopyMethod = scribev1alpha1.CopyMethodClone
+			},
+			wantDiff:     true,
+			wantSchedule: "*/3 * * * *",
+		},
+		{
+			name: "paused object keeps the trigger out of the patch",
+			live: func(rs *scribev1alpha1.ReplicationSource) {
+				rs.Annotations = map[string]string{pausedScheduleAnnotation: "*/3 * * * *"}
+				rs.Spec.Trigger = nil
+				rs.Spec.Paused = true
+			},
+			wantPaused:   true,
+			wantRecorded: "*/3 * * * *",
+		},
+		{
+			name: "paused object records the planned schedule",
+			live: func(rs *scribev1alpha1.ReplicationSource) {
+				rs.Annotations = map[string]string{pausedScheduleAnnotation: "*/3 * * * *"}
+				rs.Spec.Trigger = nil
+				rs.Spec.Paused = true
+			},
+			plan: func(plan string) string {
+				return strings.Replace(plan, `schedule: "*/3 * * * *"`, `schedule: "0 * * * *"`, 1)
+			},
+			wantDiff:     true,
+			wantPaused:   true,
+			wantRecorded: "0 * * * *",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live, _ := plannedSource(t, testPlan)
+			live.ResourceVersion = "42"
+			live.UID = types.UID("3d1f0f4e")
+			live.Generation = 2
+			live.Status = &scribev1alpha1.ReplicationSourceStatus{}
+			if tt.live != nil {
+				tt.live(live)
+			}
+			plan := testPlan
+			if tt.plan != nil {
+				plan = tt.plan(plan)
+			}
+			merged := &scribev1alpha1.ReplicationSource{}
+			planned, defaulted := plannedSource(t, plan)
+			if err := mergePlanned(live, planned, merged, defaulted); err != nil {
+				t.Fatal(err)
+			}
+			diff, err := objectDiff("replicationsource/db-source", "live", "plan", live, merged)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantDiff != (len(diff) > 0) {
+				t.Errorf("expected a diff %v, got:\n%s", tt.wantDiff, diff)
+			}
+			if merged.Spec.Paused != tt.wantPaused {
+				t.Errorf("expected paused %v, got %v", tt.wantPaused, merged.Spec.Paused)
+			}
+			gotSchedule := ""
+			if merged.Spec.Trigger != nil && merged.Spec.Trigger.Schedule != nil {
+				gotSchedule = *merged.Spec.Trigger.Schedule
+			}
+			if gotSchedule != tt.wantSchedule {
+				t.Errorf("expected schedule %q, got %q", tt.wantSchedule, gotSchedule)
+			}
+			if recorded := merged.Annotations[pausedScheduleAnnotation]; recorded != tt.wantRecorded {
+				t.Errorf("expected recorded schedule %q, got %q", tt.wantRecorded, recorded)
+			}
+			if merged.ResourceVersion != live.ResourceVersion {
+				t.Errorf("expected resourceVersion %s of the live object, got %s", live.ResourceVersion, merged.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestMergePlannedDestinationServiceType(t *testing.T) {
+	live, _ := plannedDestination(t, testPlan)
+	live.ResourceVersion = "42"
+	serviceType := corev1.ServiceTypeLoadBalancer
+	live.Spec.Rsync.ServiceType = &serviceType
+	planned, defaulted := plannedDestination(t, testPlan)
+	if *planned.Spec.Rsync.ServiceType != corev1.ServiceTypeClusterIP {
+		t.Fatalf("expected new-destination to default the service type to ClusterIP, got %s", *planned.Spec.Rsync.ServiceType)
+	}
+	merged := &scribev1alpha1.ReplicationDestination{}
+	if err := mergePlanned(live, planned, merged, defaulted); err != nil {
+		t.Fatal(err)
+	}
+	if got := *merged.Spec.Rsync.ServiceType; got != corev1.ServiceTypeLoadBalancer {
+		t.Errorf("expected the live service type LoadBalancer to be kept, got %s", got)
+	}
+}
+
+func TestLoadReplicationPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			plan: testPlan,
+		},
+		{
+			name:    "unknown field",
+			plan:    strings.Replace(testPlan, "    pvc: mysql-pv-claim", "    pvc: mysql-pv-claim\n    volume: mysql", 1),
+			wantErr: `unknown field "volume"`,
+		},
+		{
+			name: "duplicate pair name",
+			plan: testPlan + `- name: mysql
+  source:
+    pvc: mysql-logs
+    copyMethod: Clone
+  destination:
+    pvc: mysql-logs-copy
+    copyMethod: None
+`,
+			wantErr: "pair mysql: the name is used by another pair",
+		},
+		{
+			name:    "pair without name",
+			plan:    strings.Replace(testPlan, "- name: mysql", "- name: \"\"", 1),
+			wantErr: "pair 0: must have a name",
+		},
+		{
+			name:    "wrong kind",
+			plan:    strings.Replace(testPlan, "kind: ReplicationPlan", "kind: Plan", 1),
+			wantErr: "expected apiVersion scribectl.backube/v1alpha1 and kind ReplicationPlan",
+		},
+		{
+			name:    "invalid copy method",
+			plan:    strings.Replace(testPlan, "    copyMethod: Snapshot\n    capacity", "    copyMethod: Copy\n    capacity", 1),
+			wantErr: `pair mysql: destination: copyMethod must be one of 'None|Clone|Snapshot', got "Copy"`,
+		},
+		{
+			name:    "invalid schedule",
+			plan:    strings.Replace(testPlan, `schedule: "*/3 * * * *"`, `schedule: "every 3 minutes"`, 1),
+			wantErr: "pair mysql: source: invalid schedule every 3 minutes",
+		},
+		{
+			name:    "destination without volume",
+			plan:    strings.Replace(testPlan, "    capacity: 2Gi\n", "", 1),
+			wantErr: "pair mysql: destination: must either have a capacity and an accessMode or a pvc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadReplicationPlan(writeTestFile(t, tt.plan))
+			switch {
+			case len(tt.wantErr) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case len(tt.wantErr) > 0 && err == nil:
+				t.Errorf("expected error %q, got none", tt.wantErr)
+			case len(tt.wantErr) > 0 && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scribeInstallDocs documents how to install the scribe operator and its CRDs.
+const scribeInstallDocs = "https://scribe-replication.readthedocs.io/en/latest/installation/index.html"
+
+// snapshotVersions are the versions of the snapshot.storage.k8s.io API, in order of preference.
+var snapshotVersions = []string{"v1", "v1beta1"}
+
+// validateSchedule checks that the cron spec passed with flag parses.
+func validateSchedule(schedule, flag string) error {
+	if len(schedule) == 0 {
+		return nil
+	}
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		return fmt.Errorf("invalid %s %q: %v; pass the 5 fields 'minute hour day-of-month month day-of-week', such as '*/3 * * * *'", flag, schedule, err)
+	}
+	return nil
+}
+
+// validateCapacity checks that the quantity passed with flag parses.
+func validateCapacity(capacity, flag string) error {
+	if len(capacity) == 0 {
+		return nil
+	}
+	if _, err := resource.ParseQuantity(capacity); err != nil {
+		return fmt.Errorf("invalid %s %s: %v; pass a quantity such as 10Gi", flag, capacity, err)
+	}
+	return nil
+}
+
+// preflightNamespace checks that the namespace exists. The check is skipped if namespaces cannot be read.
+func preflightNamespace(ctx context.Context, c client.Client, namespace, kubeContext string) error {
+	err := c.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{})
+	switch {
+	case kerrors.IsNotFound(err):
+		return fmt.Errorf("namespace %s not found in the cluster of context %s, create it with 'kubectl --context %s create namespace %s'", namespace, kubeContext, kubeContext, namespace)
+	case kerrors.IsForbidden(err):
+		klog.V(2).Infof("unable to check namespace %s: %v", namespace, err)
+		return nil
+	}
+	return err
+}
+
+// preflightCRD checks that the CRD of the kind of list is installed by listing it in the namespace.
+func preflightCRD(ctx context.Context, c client.Client, list runtime.Object, kind, namespace, kubeContext string) error {
+	err := c.List(ctx, list, client.InNamespace(namespace), client.Limit(1))
+	switch {
+	case meta.IsNoMatchError(err):
+		return fmt.Errorf("the %s CRD is not installed in the cluster of context %s, install the Scribe operator: %s", kind, kubeContext, scribeInstallDocs)
+	case kerrors.IsForbidden(err):
+		klog.V(2).Infof("unable to check the %s CRD: %v", kind, err)
+		return nil
+	}
+	return err
+}
+
+// preflightPVC returns the PersistentVolumeClaim passed with flag, checking that it is Bound or waits for
+// the first pod using it to be bound.
+func preflightPVC(ctx context.Context, c client.Client, namespace, name, flag, kubeContext string) (*corev1.PersistentVolumeClaim, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pvc)
+	if kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%s %s: PersistentVolumeClaim not found in namespace %s of context %s, list them with 'kubectl --context %s -n %s get pvc'", flag, name, namespace, kubeContext, kubeContext, namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pvc.Status.Phase == corev1.ClaimBound {
+		return pvc, nil
+	}
+	if pvc.Status.Phase == corev1.ClaimPending && waitsForFirstConsumer(ctx, c, pvc) {
+		klog.V(2).Infof("PersistentVolumeClaim %s is Pending until a pod uses it", name)
+		return pvc, nil
+	}
+	return nil, fmt.Errorf("%s %s: PersistentVolumeClaim is %s, not Bound; see why with 'kubectl --context %s -n %s describe pvc %s'", flag, name, pvc.Status.Phase, kubeContext, namespace, name)
+}
+
+// waitsForFirstConsumer returns true if the StorageClass of the PersistentVolumeClaim binds volumes once a
+// pod uses them.
+func waitsForFirstConsumer(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim) bool {
+	if pvc.Spec.StorageClassName == nil {
+		return false
+	}
+	sc := &storagev1.StorageClass{}
+	if err := c.Get(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+		return false
+	}
+	return sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer
+}
+
+// pvcSize returns the capacity of the PersistentVolumeClaim, or its requested size if it is not bound.
+func pvcSize(pvc *corev1.PersistentVolumeClaim) resource.Quantity {
+	if size, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		return size
+	}
+	return pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+}
+
+// preflightCapacity checks that the capacity passed with flag holds the PersistentVolumeClaim.
+func preflightCapacity(capacity, flag string, pvc *corev1.PersistentVolumeClaim) error {
+	if len(capacity) == 0 || pvc == nil {
+		return nil
+	}
+	quantity, err := resource.ParseQuantity(capacity)
+	if err != nil {
+		return err
+	}
+	size := pvcSize(pvc)
+	if quantity.Cmp(size) < 0 {
+		return fmt.Errorf("%s %s is smaller than PersistentVolumeClaim %s of %s, pass at least %s", flag, capacity, pvc.Name, size.String(), size.String())
+	}
+	return nil
+}
+
+// preflightStorageClass checks that the StorageClass passed with flag exists. The check is skipped if
+// StorageClasses cannot be read.
+func preflightStorageClass(ctx context.Context, c client.Client, name, flag, kubeContext string) error {
+	if len(name) == 0 {
+		return nil
+	}
+	err := c.Get(ctx, types.NamespacedName{Name: name}, &storagev1.StorageClass{})
+	switch {
+	case kerrors.IsNotFound(err):
+		return fmt.Errorf("%s %s: StorageClass not found in the cluster of context %s, list them with 'kubectl --context %s get storageclass'", flag, name, kubeContext, kubeContext)
+	case kerrors.IsForbidden(err):
+		klog.V(2).Infof("unable to check StorageClass %s: %v", name, err)
+		return nil
+	}
+	return err
+}
+
+// preflightSnapshotClass checks that the VolumeSnapshotClass passed with flag exists or, if none was
+// passed, that the cluster has a default VolumeSnapshotClass. The check is skipped if VolumeSnapshotClasses
+// cannot be read.
+func preflightSnapshotClass(ctx context.Context, c client.Client, name, flag, kubeContext string) error {
+	vscs, err := listVolumeSnapshotClasses(ctx, c)
+	switch {
+	case meta.IsNoMatchError(err):
+		return fmt.Errorf("copy method Snapshot needs the VolumeSnapshot CRDs and a CSI snapshot controller, which are not installed in the cluster of context %s; use copy method Clone or None instead", kubeContext)
+	case kerrors.IsForbidden(err):
+		klog.V(2).Infof("unable to check the VolumeSnapshotClasses: %v", err)
+		return nil
+	case err != nil:
+		return err
+	}
+	for _, vsc := range vscs {
+		if len(name) > 0 && vsc.GetName() == name {
+			return nil
+		}
+		if len(name) == 0 && vsc.GetAnnotations()[defaultSnapshotClassAnnotation] == "true" {
+			return nil
+		}
+	}
+	if len(name) > 0 {
+		return fmt.Errorf("%s %s: VolumeSnapshotClass not found in the cluster of context %s, list them with 'kubectl --context %s get volumesnapshotclass'", flag, name, kubeContext, kubeContext)
+	}
+	return fmt.Errorf("copy method Snapshot without %s needs a default VolumeSnapshotClass and the cluster of context %s has none; pass %s or annotate a VolumeSnapshotClass with %s=true", flag, kubeContext, flag, defaultSnapshotClassAnnotation)
+}
+
+// listVolumeSnapshotClasses returns the VolumeSnapshotClasses of the first version of the snapshot API
+// served by the cluster, or a NoKindMatchError if it serves none.
+func listVolumeSnapshotClasses(ctx context.Context, c client.Client) ([]unstructured.Unstructured, error) {
+	var err error
+	for _, version := range snapshotVersions {
+		vscs := &unstructured.UnstructuredList{}
+		vscs.SetGroupVersionKind(schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: version, Kind: "VolumeSnapshotClassList"})
+		if err = c.List(ctx, vscs); err == nil {
+			return vscs.Items, nil
+		}
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// listVolumeSnapshots returns the VolumeSnapshots of the namespace from the first version of the snapshot
+// API served by the cluster, or a NoKindMatchError if it serves none.
+func listVolumeSnapshots(ctx context.Context, c client.Client, namespace string) ([]unstructured.Unstructured, error) {
+	var err error
+	for _, version := range snapshotVersions {
+		snapshots := &unstructured.UnstructuredList{}
+		snapshots.SetGroupVersionKind(schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: version, Kind: "VolumeSnapshotList"})
+		if err = c.List(ctx, snapshots, client.InNamespace(namespace)); err == nil {
+			return snapshots.Items, nil
+		}
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// getVolumeSnapshot returns the VolumeSnapshot from the first version of the snapshot API served by the
+// cluster, or a NoKindMatchError if it serves none.
+func getVolumeSnapshot(ctx context.Context, c client.Client, nsName types.NamespacedName) (*unstructured.Unstructured, error) {
+	var err error
+	for _, version := range snapshotVersions {
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: version, Kind: "VolumeSnapshot"})
+		if err = c.Get(ctx, nsName, snapshot); err == nil {
+			return snapshot, nil
+		}
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// rcloneConfigKey is the key of the rclone.conf in the secret mounted by the rclone movers.
+const rcloneConfigKey = "rclone.conf"
+
+var (
+	scribeCreateRcloneSecretLong = templates.LongDesc(`
+Create the secret holding the rclone.conf used by ReplicationSources and ReplicationDestinations with
+--mover rclone. The secret is created in the source namespace, the destination namespace, or both, in
+the cluster of each kube context. An existing secret of the same name is updated.
+
+With --rclone-config-section, only the section defining that remote is stored in the secret, so that
+the credentials of other remotes in the local rclone.conf are not copied to the clusters.
+`)
+	scribeCreateRcloneSecretExample = templates.Examples(`
+        # Create the secret 'rclone-secret' in namespaces 'source' and 'dest' with the remote 'aws-s3-bucket' of the local rclone.conf.
+        scribe create-rclone-secret --from-file ~/.config/rclone/rclone.conf --rclone-config-section aws-s3-bucket \
+            --source-namespace source --dest-namespace dest
+
+        # Create the secret 'rclone-secret' only in the namespace 'dest' of the cluster of context 'kind-kind'.
+        scribe create-rclone-secret --from-file ~/.config/rclone/rclone.conf --side destination \
+            --dest-kube-context kind-kind --dest-namespace dest
+    `)
+)
+
+type rcloneSecretOptions struct {
+	scribeOptions       scribeOptions
+	FromFile            string
+	RcloneConfig        string
+	RcloneConfigSection string
+	Side                string
+	genericclioptions.IOStreams
+}
+
+func NewRcloneSecretOptions(streams genericclioptions.IOStreams) *rcloneSecretOptions {
+	return &rcloneSecretOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeCreateRcloneSecret(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewRcloneSecretOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "create-rclone-secret [OPTIONS]",
+		Short:   i18n.T("Create the secret holding the rclone.conf in the source and destination namespaces."),
+		Long:    fmt.Sprintf(scribeCreateRcloneSecretLong),
+		Example: fmt.Sprintf(scribeCreateRcloneSecretExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete())
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.CreateRcloneSecret())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *rcloneSecretOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.FromFile, "from-file", o.FromFile, "path of the local rclone.conf to store in the secret.")
+	flags.StringVar(&o.RcloneConfig, "rclone-config", "rclone-secret", "name of the secret to create, as passed to --rclone-config of new-source and new-destination.")
+	flags.StringVar(&o.RcloneConfigSection, "rclone-config-section", o.RcloneConfigSection, "name of the remote to keep from the rclone.conf. If not set, the whole file is stored.")
+	flags.StringVar(&o.Side, "side", "both", "where to create the secret; one of 'source|destination|both'")
+	cmd.MarkFlagRequired("from-file")
+}
+
+func (o *rcloneSecretOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *rcloneSecretOptions) Complete() error {
+	return o.scribeOptions.Complete()
+}
+
+// Validate validates create-rclone-secret options.
+func (o *rcloneSecretOptions) Validate() error {
+	if len(o.FromFile) == 0 {
+		return fmt.Errorf("must provide --from-file, the path of the rclone.conf")
+	}
+	if len(o.RcloneConfig) == 0 {
+		return fmt.Errorf("must provide --rclone-config, the name of the secret")
+	}
+	return validateSide(o.Side)
+}
+
+// CreateRcloneSecret creates or updates the secret on the selected sides.
+func (o *rcloneSecretOptions) CreateRcloneSecret() error {
+	ctx := context.Background()
+	config, err := ioutil.ReadFile(o.FromFile)
+	if err != nil {
+		return err
+	}
+	if len(o.RcloneConfigSection) > 0 {
+		if config, err = rcloneConfigSection(config, o.RcloneConfigSection); err != nil {
+			return fmt.Errorf("%s: %v", o.FromFile, err)
+		}
+	}
+	data := map[string][]byte{rcloneConfigKey: config}
+	for _, c := range o.scribeOptions.secretClients(o.Side) {
+		if err := applySecret(ctx, c.client, c.namespace, o.RcloneConfig, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rcloneConfigSection returns the section of the rclone.conf defining the remote.
+func rcloneConfigSection(config []byte, remote string) ([]byte, error) {
+	var section bytes.Buffer
+	remotes := []string{}
+	inSection := false
+	scanner := bufio.NewScanner(bytes.NewReader(config))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			remotes = append(remotes, name)
+			inSection = name == remote
+		}
+		if inSection {
+			section.WriteString(line + "\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if section.Len() == 0 {
+		return nil, fmt.Errorf("remote %s not found, the rclone.conf defines [%s]", remote, strings.Join(remotes, ", "))
+	}
+	return section.Bytes(), nil
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeReplicateLong = templates.LongDesc(`
+Set up replication of a persistent volume in one step. Replicate creates the ReplicationDestination,
+waits for it to publish its address, copies the SSH keys secret to the source namespace and creates
+the ReplicationSource connecting to the ReplicationDestination.
+
+With --mover rclone, the ReplicationSource and the ReplicationDestination sync through the rclone remote
+instead, so there is no address to wait for and no SSH keys secret to copy. The secret passed with
+--rclone-config must exist in both namespaces.
+
+If a step fails, the objects created by the previous steps are deleted.
+`)
+	scribeReplicateExample = templates.Examples(`
+        # Replicate mysql-pvc from namespace 'source' to namespace 'dest' in the same cluster.
+        scribe replicate --source-namespace source --source-pvc mysql-pvc --source-copy-method Snapshot \
+            --dest-namespace dest --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce
+
+        # Replicate mysql-pvc from the cluster of context 'admin' to the cluster of context 'kind-kind'.
+        scribe replicate --source-kube-context admin --source-namespace source --source-pvc mysql-pvc \
+            --source-copy-method Snapshot --dest-kube-context kind-kind --dest-namespace dest \
+            --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce --dest-service-type LoadBalancer
+
+        # Replicate mysql-pvc through the bucket 'scribe-mysql' of the rclone remote 'aws-s3-bucket'.
+        scribe replicate --source-namespace source --source-pvc mysql-pvc --source-copy-method Snapshot \
+            --dest-namespace dest --dest-copy-method Snapshot --dest-access-mode ReadWriteOnce \
+            --mover rclone --rclone-config rclone-secret --rclone-config-section aws-s3-bucket --rclone-dest-path scribe-mysql
+    `)
+)
+
+type replicateOptions struct {
+	scribeOptions        scribeOptions
+	destinationOptions   destinationOptions
+	sshKeysSecretOptions sshKeysSecretOptions
+	sourceOptions        sourceOptions
+	genericclioptions.IOStreams
+}
+
+func NewReplicateOptions(streams genericclioptions.IOStreams) *replicateOptions {
+	return &replicateOptions{
+		destinationOptions:   destinationOptions{IOStreams: streams},
+		sshKeysSecretOptions: sshKeysSecretOptions{IOStreams: streams},
+		sourceOptions:        sourceOptions{IOStreams: streams},
+		IOStreams:            streams,
+	}
+}
+
+func NewCmdScribeReplicate(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewReplicateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "replicate [OPTIONS]",
+		Short:   i18n.T("Create a ReplicationDestination, sync the SSH secret and create a ReplicationSource."),
+		Long:    fmt.Sprintf(scribeReplicateLong),
+		Example: fmt.Sprintf(scribeReplicateExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Replicate())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *replicateOptions) bindFlags(cmd *cobra.Command) {
+	o.destinationOptions.bindDestinationFlags(cmd)
+	o.sourceOptions.bindSourceFlags(cmd)
+	flags := cmd.Flags()
+	// defaults to 22 after creation
+	flags.Int32Var(&o.sourceOptions.Port, "port", o.sourceOptions.Port, "SSH port of the ReplicationDestination for replication. (default 22)")
+	// defaults to "/" after creation
+	flags.StringVar(&o.sourceOptions.Path, "path", o.sourceOptions.Path, "the remote path to rsync to (default '/')")
+	flags.DurationVar(&o.sourceOptions.AddressTimeout, "address-timeout", 5*time.Minute, "how long to wait for the ReplicationDestination to publish its address.")
+	o.sourceOptions.bindMoverFlags(cmd)
+	cmd.MarkFlagRequired("dest-copy-method")
+	cmd.MarkFlagRequired("source-copy-method")
+	cmd.MarkFlagRequired("source-pvc")
+}
+
+func (o *replicateOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *replicateOptions) Complete(cmd *cobra.Command) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	o.sshKeysSecretOptions.scribeOptions = o.scribeOptions
+	return completePair(cmd, o.scribeOptions, &o.destinationOptions, &o.sourceOptions)
+}
+
+// completePair completes the destination and source options of a pair described by the replicate flags.
+// The mover flags are bound to the source options and shared with the destination.
+func completePair(cmd *cobra.Command, so scribeOptions, d *destinationOptions, s *sourceOptions) error {
+	d.scribeOptions = so
+	s.scribeOptions = so
+	d.Mover = s.Mover
+	d.RcloneConfig = s.RcloneConfig
+	d.RcloneConfigSection = s.RcloneConfigSection
+	d.RcloneDestPath = s.RcloneDestPath
+	if err := d.Complete(cmd); err != nil {
+		return err
+	}
+	return s.Complete(cmd)
+}
+
+// Validate validates the ReplicationDestination and ReplicationSource options and checks them against
+// both clusters, including that the destination volume holds --source-pvc. The SSH keys secret and
+// address of the ReplicationSource are only known once the ReplicationDestination exists.
+func (o *replicateOptions) Validate() error {
+	if err := validatePairFlags(&o.destinationOptions, &o.sourceOptions); err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	destPVC, err := o.destinationOptions.preflight(ctx)
+	if err != nil {
+		return err
+	}
+	sourcePVC, err := o.sourceOptions.preflight(ctx)
+	if err != nil {
+		return err
+	}
+	if destPVC == nil {
+		return preflightCapacity(o.destinationOptions.DestCapacity, "--dest-capacity", sourcePVC)
+	}
+	destSize, sourceSize := pvcSize(destPVC), pvcSize(sourcePVC)
+	if destSize.Cmp(sourceSize) < 0 {
+		return fmt.Errorf("--dest-pvc %s of %s is smaller than --source-pvc %s of %s, pass a PersistentVolumeClaim of at least %s", destPVC.Name, destSize.String(), sourcePVC.Name, sourceSize.String(), sourceSize.String())
+	}
+	return nil
+}
+
+// validatePairFlags validates the flags of a pair described by the replicate flags, without checking them
+// against the clusters.
+func validatePairFlags(d *destinationOptions, s *sourceOptions) error {
+	if err := d.validateFlags(); err != nil {
+		return err
+	}
+	if len(s.SourceCopyMethod) == 0 {
+		return fmt.Errorf("must provide --source-copy-method; one of 'None|Clone|Snapshot'")
+	}
+	if len(s.SourcePVC) == 0 {
+		return fmt.Errorf("must provide --source-pvc, the PersistentVolumeClaim to replicate")
+	}
+	return s.validateVolumeFlags()
+}
+
+// Replicate runs each step of the replication set up, deleting what was created if a step fails.
+func (o *replicateOptions) Replicate() error {
+	ctx := context.Background()
+	created := []*deleteItem{}
+	type step struct {
+		description string
+		run         func(context.Context) (*deleteItem, error)
+	}
+	steps := []step{{"create ReplicationDestination", o.createDestination}}
+	// rclone movers only share the remote
+	if o.sourceOptions.Mover != "rclone" {
+		steps = append(steps,
+			step{"wait for ReplicationDestination address", o.waitForAddress},
+			step{"sync SSH keys secret", o.syncSSHSecret},
+		)
+	}
+	steps = append(steps, step{"create ReplicationSource", o.createSource})
+	for i, step := range steps {
+		klog.V(0).Infof("[%d/%d] %s", i+1, len(steps), step.description)
+		item, err := step.run(ctx)
+		if err != nil {
+			klog.Errorf("[%d/%d] %s failed: %v", i+1, len(steps), step.description, err)
+			return o.rollback(ctx, created, err)
+		}
+		if item != nil {
+			created = append(created, item)
+		}
+	}
+	klog.V(0).Infof("ReplicationSource %s in namespace %s replicates to ReplicationDestination %s in namespace %s",
+		o.sourceOptions.SourceName, o.sourceOptions.SourceNamespace, o.destinationOptions.DestName, o.destinationOptions.DestNamespace)
+	return nil
+}
+
+// rollback deletes the created objects in reverse order and returns the error that caused it.
+func (o *replicateOptions) rollback(ctx context.Context, created []*deleteItem, cause error) error {
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := created[i].client.Delete(ctx, created[i].obj); err != nil && !kerrors.IsNotFound(err) {
+			klog.Errorf("rollback: unable to delete %s: %v", created[i], err)
+			continue
+		}
+		klog.V(0).Infof("rollback: deleted %s", created[i])
+	}
+	return cause
+}
+
+func (o *replicateOptions) createDestination(ctx context.Context) (*deleteItem, error) {
+	if err := o.destinationOptions.CreateReplicationDestination(); err != nil {
+		return nil, err
+	}
+	rd := &scribev1alpha1.ReplicationDestination{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: scribev1alpha1.GroupVersion.String(),
+			Kind:       "ReplicationDestination",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.destinationOptions.DestName,
+			Namespace: o.destinationOptions.DestNamespace,
+		},
+	}
+	return &deleteItem{side: "destination", client: o.scribeOptions.DestinationClient, obj: rd}, nil
+}
+
+// waitForAddress waits for the ReplicationDestination to publish its address, then sets the address and
+// SSH keys secret of the ReplicationSource.
+func (o *replicateOptions) waitForAddress(ctx context.Context) (*deleteItem, error) {
+	nsName := types.NamespacedName{
+		Namespace: o.destinationOptions.DestNamespace,
+		Name:      o.destinationOptions.DestName,
+	}
+	timeout := o.sourceOptions.AddressTimeout
+	klog.V(0).Infof("waiting up to %s for ReplicationDestination %s in namespace %s to publish its address", timeout, nsName.Name, nsName.Namespace)
+	rd, err := waitForDestination(ctx, o.scribeOptions.DestinationClient, nsName, timeout, destinationHasAddress)
+	if err == wait.ErrWaitTimeout {
+		return nil, fmt.Errorf("timed out after %s waiting for ReplicationDestination %s in namespace %s to publish its address, retry with a longer --address-timeout", timeout, nsName.Name, nsName.Namespace)
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.sourceOptions.Address = *rd.Status.Rsync.Address
+	o.sourceOptions.sshKeysSecretOptions.SSHKeysSecret = destinationSSHKeysSecret(rd)
+	return nil, nil
+}
+
+func (o *replicateOptions) syncSSHSecret(ctx context.Context) (*deleteItem, error) {
+	o.sshKeysSecretOptions.SSHKeysSecret = o.sourceOptions.sshKeysSecretOptions.SSHKeysSecret
+	copied, err := o.sshKeysSecretOptions.syncSSHSecretIfMissing(ctx)
+	if err != nil || !copied {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.sshKeysSecretOptions.SSHKeysSecret,
+			Namespace: o.scribeOptions.sourceNamespace,
+		},
+	}
+	return &deleteItem{side: "source", client: o.scribeOptions.SourceClient, obj: secret}, nil
+}
+
+func (o *replicateOptions) createSource(ctx context.Context) (*deleteItem, error) {
+	// the clusters were checked by Validate, only the address and SSH keys secret are new
+	if err := o.sourceOptions.validateFlags(); err != nil {
+		return nil, err
+	}
+	if err := o.sourceOptions.CreateReplicationSource(); err != nil {
+		return nil, err
+	}
+	rs := &scribev1alpha1.ReplicationSource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: scribev1alpha1.GroupVersion.String(),
+			Kind:       "ReplicationSource",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.sourceOptions.SourceName,
+			Namespace: o.sourceOptions.SourceNamespace,
+		},
+	}
+	return &deleteItem{side: "source", client: o.scribeOptions.SourceClient, obj: rs}, nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// resticEnvVars are the environment variables restic reads for the repository and the
+// credentials of its storage backends.
+var resticEnvVars = []string{
+	"RESTIC_REPOSITORY",
+	"RESTIC_PASSWORD",
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_DEFAULT_REGION",
+	"B2_ACCOUNT_ID",
+	"B2_ACCOUNT_KEY",
+	"AZURE_ACCOUNT_NAME",
+	"AZURE_ACCOUNT_KEY",
+	"GOOGLE_PROJECT_ID",
+}
+
+var (
+	scribeCreateResticSecretLong = templates.LongDesc(`
+Create the secret describing a restic repository from the restic environment variables of the current
+shell. RESTIC_REPOSITORY and RESTIC_PASSWORD are required; the credentials of the storage backend, such
+as AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, are copied when set. The secret is created in the source
+namespace, the destination namespace, or both. An existing secret of the same name is updated.
+
+The restic mover is not available in the scribe API this version of scribe is built with, so new-source
+and new-destination cannot use the secret yet.
+`)
+	scribeCreateResticSecretExample = templates.Examples(`
+        # Create the secret 'restic-config' in namespace 'source' for a restic repository in an S3 bucket.
+        export RESTIC_REPOSITORY=s3:s3.amazonaws.com/scribe-backups RESTIC_PASSWORD=... \
+            AWS_ACCESS_KEY_ID=... AWS_SECRET_ACCESS_KEY=...
+        scribe create-restic-secret --side source --source-namespace source
+    `)
+)
+
+type resticSecretOptions struct {
+	scribeOptions    scribeOptions
+	ResticRepository string
+	Side             string
+	genericclioptions.IOStreams
+}
+
+func NewResticSecretOptions(streams genericclioptions.IOStreams) *resticSecretOptions {
+	return &resticSecretOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeCreateResticSecret(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewResticSecretOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "create-restic-secret [OPTIONS]",
+		Short:   i18n.T("Create the secret describing a restic repository from the restic environment variables."),
+		Long:    fmt.Sprintf(scribeCreateResticSecretLong),
+		Example: fmt.Sprintf(scribeCreateResticSecretExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete())
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.CreateResticSecret())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *resticSecretOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.ResticRepository, "restic-repository", "restic-config", "name of the secret to create.")
+	flags.StringVar(&o.Side, "side", "both", "where to create the secret; one of 'source|destination|both'")
+}
+
+func (o *resticSecretOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *resticSecretOptions) Complete() error {
+	return o.scribeOptions.Complete()
+}
+
+// Validate validates create-restic-secret options and the restic environment.
+func (o *resticSecretOptions) Validate() error {
+	if len(o.ResticRepository) == 0 {
+		return fmt.Errorf("must provide --restic-repository, the name of the secret")
+	}
+	for _, name := range []string{"RESTIC_REPOSITORY", "RESTIC_PASSWORD"} {
+		if len(os.Getenv(name)) == 0 {
+			return fmt.Errorf("environment variable %s must be set", name)
+		}
+	}
+	return validateSide(o.Side)
+}
+
+// CreateResticSecret creates or updates the secret on the selected sides.
+func (o *resticSecretOptions) CreateResticSecret() error {
+	ctx := context.Background()
+	data := map[string][]byte{}
+	for _, name := range resticEnvVars {
+		if value := os.Getenv(name); len(value) > 0 {
+			data[name] = []byte(value)
+		}
+	}
+	for _, c := range o.scribeOptions.secretClients(o.Side) {
+		if err := applySecret(ctx, c.client, c.namespace, o.ResticRepository, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeRestoreLong = templates.LongDesc(`
+Create a PersistentVolumeClaim in the destination namespace from the latest image of a ReplicationDestination.
+The latest image is a VolumeSnapshot with the Snapshot copy method, or a PersistentVolumeClaim that is cloned
+otherwise.
+
+With --replace, an existing PersistentVolumeClaim of the same name is deleted first and a new volume is
+provisioned from the latest image, instead of rebinding the old PersistentVolume. The claim must not be
+in use: scale down the workloads mounting it before restoring, and scale them back up afterwards. The
+reclaim policy of the old PersistentVolume is set to Retain before the claim is deleted, so that its data
+is kept until the volume is deleted by hand.
+`)
+	scribeRestoreExample = templates.Examples(`
+        # Create the PVC mysql-pv-claim in namespace 'dest' from the latest image of ReplicationDestination 'dest-destination'.
+        scribe restore dest-destination --dest-namespace dest --restore-pvc mysql-pv-claim
+
+        # Replace the PVC mysql-pv-claim with the latest image, after the mysql deployment was scaled down.
+        kubectl scale deployment/mysql -n dest --replicas 0
+        scribe restore dest-destination --dest-namespace dest --restore-pvc mysql-pv-claim --replace
+        kubectl scale deployment/mysql -n dest --replicas 1
+    `)
+)
+
+type restoreOptions struct {
+	scribeOptions    scribeOptions
+	DestName         string
+	RestorePVC       string
+	Capacity         string
+	StorageClassName string
+	AccessMode       string
+	Replace          bool
+	Timeout          time.Duration
+	genericclioptions.IOStreams
+}
+
+func NewRestoreOptions(streams genericclioptions.IOStreams) *restoreOptions {
+	return &restoreOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeRestore(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewRestoreOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "restore [NAME] [OPTIONS]",
+		Short:   i18n.T("Create a PersistentVolumeClaim from the latest image of a ReplicationDestination."),
+		Long:    fmt.Sprintf(scribeRestoreLong),
+		Example: fmt.Sprintf(scribeRestoreExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Restore())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *restoreOptions) bindFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.DestName, "dest-name", o.DestName, "name of the ReplicationDestination to restore from, if not passed as an argument. (default '<dest-namespace>-destination')")
+	flags.StringVar(&o.RestorePVC, "restore-pvc", o.RestorePVC, "name of the PersistentVolumeClaim to create from the latest image, in the namespace of the ReplicationDestination.")
+	flags.StringVar(&o.Capacity, "capacity", o.Capacity, "size of the restored volume. If not set, the size of the latest image is used.")
+	flags.StringVar(&o.StorageClassName, "storage-class-name", o.StorageClassName, "name of the StorageClass of the restored volume. If not set, the StorageClass of the replaced claim or of the ReplicationDestination is used.")
+	flags.StringVar(&o.AccessMode, "access-mode", o.AccessMode, "the access mode of the restored volume; one of 'ReadWriteOnce|ReadOnlyMany|ReadWriteMany'. If not set, the access modes of the replaced claim or of the ReplicationDestination are used.")
+	flags.BoolVar(&o.Replace, "replace", o.Replace, "delete the PersistentVolumeClaim if it exists, retaining its PersistentVolume, and create it again from the latest image.")
+	flags.DurationVar(&o.Timeout, "timeout", 2*time.Minute, "how long to wait for the replaced PersistentVolumeClaim to be deleted.")
+	cmd.MarkFlagRequired("restore-pvc")
+}
+
+func (o *restoreOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *restoreOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	pair := &pairOptions{DestName: o.DestName}
+	if err := pair.Complete(cmd, args, o.scribeOptions.destNamespace); err != nil {
+		return err
+	}
+	o.DestName = pair.DestName
+	return nil
+}
+
+// Validate validates restore options.
+func (o *restoreOptions) Validate() error {
+	if len(o.RestorePVC) == 0 {
+		return fmt.Errorf("must provide --restore-pvc, the name of the PersistentVolumeClaim to create")
+	}
+	if len(o.Capacity) > 0 {
+		if _, err := resource.ParseQuantity(o.Capacity); err != nil {
+			return fmt.Errorf("invalid --capacity %s: %v", o.Capacity, err)
+		}
+	}
+	if len(o.AccessMode) > 0 {
+		if _, err := parseAccessMode(o.AccessMode); err != nil {
+			return fmt.Errorf("invalid --access-mode: %v", err)
+		}
+	}
+	return nil
+}
+
+// Restore creates, or replaces, the PersistentVolumeClaim from the latest image.
+func (o *restoreOptions) Restore() error {
+	ctx := context.Background()
+	c := o.scribeOptions.DestinationClient
+	namespace := o.scribeOptions.destNamespace
+	rd := &scribev1alpha1.ReplicationDestination{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: o.DestName}, rd); err != nil {
+		return err
+	}
+	if rd.Status == nil || rd.Status.LatestImage == nil {
+		return fmt.Errorf("ReplicationDestination %s has no latest image yet, wait for a sync to complete", rd.Name)
+	}
+	image := rd.Status.LatestImage
+	if image.Name == o.RestorePVC {
+		return fmt.Errorf("PersistentVolumeClaim %s is the latest image of ReplicationDestination %s and cannot be restored onto itself", image.Name, rd.Name)
+	}
+
+	existing := &corev1.PersistentVolumeClaim{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: o.RestorePVC}, existing)
+	switch {
+	case kerrors.IsNotFound(err):
+		existing = nil
+	case err != nil:
+		return err
+	case !o.Replace:
+		return fmt.Errorf("PersistentVolumeClaim %s already exists in namespace %s, pass --replace to replace it with the latest image", o.RestorePVC, namespace)
+	}
+	pvc, err := o.newRestorePVC(ctx, c, rd, existing)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if err := o.deleteClaim(ctx, c, existing); err != nil {
+			return err
+		}
+	}
+	if err := c.Create(ctx, pvc); err != nil {
+		return err
+	}
+	klog.V(0).Infof("PersistentVolumeClaim %s created in namespace %s from %s %s", pvc.Name, pvc.Namespace, image.Kind, image.Name)
+	return nil
+}
+
+// newRestorePVC returns a PersistentVolumeClaim with the latest image of the ReplicationDestination as data source.
+// The labels, access modes and StorageClass of the claim it replaces are kept unless set with flags.
+func (o *restoreOptions) newRestorePVC(ctx context.Context, c client.Client, rd *scribev1alpha1.ReplicationDestination, existing *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	image := rd.Status.LatestImage
+	var volumeOptions scribev1alpha1.ReplicationDestinationVolumeOptions
+	switch {
+	case rd.Spec.Rsync != nil:
+		volumeOptions = rd.Spec.Rsync.ReplicationDestinationVolumeOptions
+	case rd.Spec.Rclone != nil:
+		volumeOptions = rd.Spec.Rclone.ReplicationDestinationVolumeOptions
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.RestorePVC,
+			Namespace: rd.Namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      volumeOptions.AccessModes,
+			StorageClassName: volumeOptions.StorageClassName,
+			DataSource: &corev1.TypedLocalObjectReference{
+				Kind: image.Kind,
+				Name: image.Name,
+			},
+		},
+	}
+	if existing != nil {
+		pvc.Labels = existing.Labels
+		if len(existing.Spec.AccessModes) > 0 {
+			pvc.Spec.AccessModes = existing.Spec.AccessModes
+		}
+		if existing.Spec.StorageClassName != nil {
+			pvc.Spec.StorageClassName = existing.Spec.StorageClassName
+		}
+	}
+	// the core API group is empty in the latest image but must be unset in a data source
+	if image.APIGroup != nil && len(*image.APIGroup) > 0 {
+		pvc.Spec.DataSource.APIGroup = image.APIGroup
+	}
+
+	// the restored volume must be at least as large as the latest image
+	var capacity *resource.Quantity
+	nsName := types.NamespacedName{Namespace: rd.Namespace, Name: image.Name}
+	switch image.Kind {
+	case "PersistentVolumeClaim":
+		imagePVC := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, nsName, imagePVC); err != nil {
+			return nil, err
+		}
+		if size, ok := imagePVC.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			capacity = &size
+		}
+		if len(pvc.Spec.AccessModes) == 0 {
+			pvc.Spec.AccessModes = imagePVC.Spec.AccessModes
+		}
+	case "VolumeSnapshot":
+		snapshot, err := getVolumeSnapshot(ctx, c, nsName)
+		if err != nil {
+			return nil, err
+		}
+		ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		if !ready {
+			return nil, fmt.Errorf("VolumeSnapshot %s is not ready to use yet", image.Name)
+		}
+		if restoreSize, ok, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize"); ok {
+			size, err := resource.ParseQuantity(restoreSize)
+			if err != nil {
+				return nil, err
+			}
+			capacity = &size
+		}
+	default:
+		return nil, fmt.Errorf("unsupported latest image %s %s", image.Kind, image.Name)
+	}
+	if capacity == nil {
+		capacity = volumeOptions.Capacity
+	}
+	if len(o.Capacity) > 0 {
+		size := resource.MustParse(o.Capacity)
+		capacity = &size
+	}
+	if capacity == nil {
+		return nil, fmt.Errorf("unable to determine the size of %s %s, pass --capacity", image.Kind, image.Name)
+	}
+	pvc.Spec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: *capacity,
+	}
+	if len(o.StorageClassName) > 0 {
+		pvc.Spec.StorageClassName = &o.StorageClassName
+	}
+	if len(o.AccessMode) > 0 {
+		accessModes, _ := parseAccessMode(o.AccessMode)
+		pvc.Spec.AccessModes = accessModes
+	}
+	if len(pvc.Spec.AccessModes) == 0 {
+		pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return pvc, nil
+}
+
+// deleteClaim deletes the PersistentVolumeClaim once no pod mounts it, and waits until it is gone.
+// The PersistentVolume it was bound to is retained first, so that the replaced data can still be recovered,
+// and is not reused.
+func (o *restoreOptions) deleteClaim(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim) error {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(pvc.Namespace)); err != nil {
+		return err
+	}
+	users := []string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvc.Name {
+				users = append(users, pod.Name)
+			}
+		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("PersistentVolumeClaim %s is in use by pod(s) %s, scale down the workloads using it before restoring", pvc.Name, strings.Join(users, ", "))
+	}
+
+	if err := retainVolume(ctx, c, pvc); err != nil {
+		return err
+	}
+	if err := c.Delete(ctx, pvc); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	klog.V(0).Infof("waiting up to %s for PersistentVolumeClaim %s to be deleted", o.Timeout, pvc.Name)
+	nsName := types.NamespacedName{Namespace: pvc.Namespace, Name: pvc.Name}
+	err := wait.PollImmediate(pollInterval, o.Timeout, func() (bool, error) {
+		err := c.Get(ctx, nsName, &corev1.PersistentVolumeClaim{})
+		if kerrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %s waiting for PersistentVolumeClaim %s to be deleted, check that no pod is still using it", o.Timeout, pvc.Name)
+	}
+	return err
+}
+
+// retainVolume sets the reclaim policy of the PersistentVolume bound to the claim to Retain, so that it is
+// not deleted with the claim, and logs which volume is kept.
+func retainVolume(ctx context.Context, c client.Client, pvc *corev1.PersistentVolumeClaim) error {
+	if len(pvc.Spec.VolumeName) == 0 {
+		return nil
+	}
+	pv := &corev1.PersistentVolume{}
+	if err := c.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, pv); err != nil {
+		return fmt.Errorf("unable to get PersistentVolume %s of PersistentVolumeClaim %s: %v", pvc.Spec.VolumeName, pvc.Name, err)
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain {
+		klog.V(0).Infof("PersistentVolume %s of the replaced claim is kept, its reclaim policy is Retain", pv.Name)
+		return nil
+	}
+	patched := pv.DeepCopy()
+	patched.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	if err := c.Patch(ctx, patched, client.MergeFrom(pv)); err != nil {
+		return fmt.Errorf("unable to retain PersistentVolume %s of PersistentVolumeClaim %s: %v", pv.Name, pvc.Name, err)
+	}
+	klog.V(0).Infof("PersistentVolume %s of the replaced claim is kept, its reclaim policy was changed from %s to Retain; delete it once the restored data is checked", pv.Name, pv.Spec.PersistentVolumeReclaimPolicy)
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRetainVolume(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy corev1.PersistentVolumeReclaimPolicy
+		want   corev1.PersistentVolumeReclaimPolicy
+	}{
+		{"delete policy is changed to retain", corev1.PersistentVolumeReclaimDelete, corev1.PersistentVolumeReclaimRetain},
+		{"retain policy is kept", corev1.PersistentVolumeReclaimRetain, corev1.PersistentVolumeReclaimRetain},
+		{"recycle policy is changed to retain", corev1.PersistentVolumeReclaimRecycle, corev1.PersistentVolumeReclaimRetain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc-3d1f0f4e"},
+				Spec:       corev1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: tt.policy},
+			}
+			c := fake.NewFakeClientWithScheme(scheme, pv)
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "mysql-pv-claim", Namespace: "db"},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: pv.Name},
+			}
+			if err := retainVolume(ctx, c, pvc); err != nil {
+				t.Fatal(err)
+			}
+			got := &corev1.PersistentVolume{}
+			if err := c.Get(ctx, types.NamespacedName{Name: pv.Name}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Spec.PersistentVolumeReclaimPolicy != tt.want {
+				t.Errorf("expected reclaim policy %s, got %s", tt.want, got.Spec.PersistentVolumeReclaimPolicy)
+			}
+		})
+	}
+}
+
+func TestRetainVolumeUnboundClaim(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme)
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "mysql-pv-claim", Namespace: "db"}}
+	if err := retainVolume(context.Background(), c, pvc); err != nil {
+		t.Errorf("expected no error for a claim that is not bound, got %v", err)
+	}
+}
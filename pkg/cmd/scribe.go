@@ -6,11 +6,14 @@ import (
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,8 +32,18 @@ Scribe is a command line tool for a scribe operator running in a Kubernetes clus
     To see the full list of commands supported, run 'scribe --help'.`)
 
 	scribeConfig = "scribe-config"
+
+	// scheme holds the types read and written by the scribe clients
+	scheme = runtime.NewScheme()
 )
 
+func init() {
+	utilruntime.Must(scribev1alpha1.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(batchv1.AddToScheme(scheme))
+	utilruntime.Must(storagev1.AddToScheme(scheme))
+}
+
 type scribeOptions struct {
 	destKubeContext       string
 	sourceKubeContext     string
@@ -40,11 +53,14 @@ type scribeOptions struct {
 	sourceNamespace       string
 	DestinationClient     client.Client
 	SourceClient          client.Client
+	// the clientsets read what the clients cannot, such as the logs of pods
+	DestinationClientset kubernetes.Interface
+	SourceClientset      kubernetes.Interface
 
 	genericclioptions.IOStreams
 }
 
-func (o *scribeOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) {
+func (o *scribeOptions) bindFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.StringVar(&o.destKubeContext, "dest-kube-context", o.destKubeContext, "the name of the kubeconfig context to use for the destination cluster. Defaults to current-context.")
 	flags.StringVar(&o.sourceKubeContext, "source-kube-context", o.sourceKubeContext, "the name of the kubeconfig context to use for the destination cluster. Defaults to current-context.")
@@ -52,27 +68,12 @@ func (o *scribeOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) {
 	flags.StringVar(&o.sourceKubeClusterName, "source-kube-clustername", o.sourceKubeClusterName, "the name of the kubeconfig cluster to use for the destination cluster. Defaults to current cluster.")
 	flags.StringVar(&o.destNamespace, "dest-namespace", o.destNamespace, "the transfer destination namespace and/or location of a ReplicationDestination. This namespace must exist. If not set, use the current namespace.")
 	flags.StringVar(&o.sourceNamespace, "source-namespace", o.sourceNamespace, "the transfer source namespace and/or location of a ReplicationSource. This namespace must exist. If not set, use the current namespace.")
-	flags.VisitAll(func(f *pflag.Flag) {
-		// Apply the viper config value to the flag when the flag is not set and viper has a value
-		if v.IsSet(f.Name) {
-			val := v.Get(f.Name)
-			flags.Set(f.Name, fmt.Sprintf("%v", val))
-		}
-	})
 }
 
 func (o *scribeOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
-	// config file in current directory
-	// TODO: where to look for config file
-	v.SetConfigName(scribeConfig)
-	v.AddConfigPath(".")
-	v.SetConfigType("yaml")
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
-		}
-	}
-	o.bindFlags(cmd, v)
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
 	return nil
 }
 
@@ -91,16 +92,44 @@ func NewCmdScribe(in io.Reader, out, errout io.Writer) *cobra.Command {
 			fmt.Fprintf(errout, "%s\n\n%s\n", scribeLong, scribeExplain)
 		},
 	}
+	cmds.PersistentFlags().StringVar(&configFile, "config", configFile, "path of the scribe config. If not set, use "+scribeConfigEnv+" or look for "+scribeConfig+" in the current directory, $XDG_CONFIG_HOME/scribe/ and $HOME/.scribe/.")
+	cmds.PersistentFlags().StringVar(&configProfile, "profile", configProfile, "name of the profile of the scribe config to use. The top level of the scribe config applies to all profiles.")
 	// TODO: Maybe pass --dest-kube-context and --source-kube-context and get 2 factories?
 	// For switching contexts: https://github.com/kubernetes/client-go/issues/192#issuecomment-362775792
 	cmds.AddCommand(NewCmdScribeNewDestination(streams))
 	cmds.AddCommand(NewCmdScribeNewSource(streams))
 	cmds.AddCommand(NewCmdScribeSyncSSHSecret(streams))
+	cmds.AddCommand(NewCmdScribeGet(streams))
+	cmds.AddCommand(NewCmdScribeDescribe(streams))
+	cmds.AddCommand(NewCmdScribeDelete(streams))
+	cmds.AddCommand(NewCmdScribeReplicate(streams))
+	cmds.AddCommand(NewCmdScribeRestore(streams))
+	cmds.AddCommand(NewCmdScribeCreateRcloneSecret(streams))
+	cmds.AddCommand(NewCmdScribeCreateResticSecret(streams))
+	cmds.AddCommand(NewCmdScribeStatus(streams))
+	cmds.AddCommand(NewCmdScribeWait(streams))
+	cmds.AddCommand(NewCmdScribeSyncNow(streams))
+	cmds.AddCommand(NewCmdScribePause(streams))
+	cmds.AddCommand(NewCmdScribeResume(streams))
+	cmds.AddCommand(NewCmdScribeUpdateSource(streams))
+	cmds.AddCommand(NewCmdScribeUpdateDestination(streams))
+	cmds.AddCommand(NewCmdScribeApply(streams))
+	cmds.AddCommand(NewCmdScribeDiff(streams))
+	cmds.AddCommand(NewCmdScribeExport(streams))
+	cmds.AddCommand(NewCmdScribeConfig(streams))
+	cmds.AddCommand(NewCmdScribeInit(streams))
+	cmds.AddCommand(NewCmdScribeDoctor(streams))
+	cmds.AddCommand(NewCmdScribeLogs(streams))
+	cmds.AddCommand(NewCmdScribeEvents(streams))
 
 	return cmds
 }
 
 func (o *scribeOptions) Complete() error {
+	if o.DestinationClient != nil && o.SourceClient != nil {
+		// already completed by a command composing several options
+		return nil
+	}
 	destKubeConfigFlags := genericclioptions.NewConfigFlags(true)
 	if len(o.destKubeContext) > 0 {
 		destKubeConfigFlags.Context = &o.destKubeContext
@@ -117,6 +146,17 @@ func (o *scribeOptions) Complete() error {
 	}
 	destf := kcmdutil.NewFactory(destKubeConfigFlags)
 	sourcef := kcmdutil.NewFactory(sourceKubeConfigFlags)
+	// record the current-context names so they can be displayed
+	if len(o.destKubeContext) == 0 {
+		if rawConfig, err := destf.ToRawKubeConfigLoader().RawConfig(); err == nil {
+			o.destKubeContext = rawConfig.CurrentContext
+		}
+	}
+	if len(o.sourceKubeContext) == 0 {
+		if rawConfig, err := sourcef.ToRawKubeConfigLoader().RawConfig(); err == nil {
+			o.sourceKubeContext = rawConfig.CurrentContext
+		}
+	}
 
 	// get client and namespace
 	destClientConfig, err := destf.ToRESTConfig()
@@ -127,9 +167,6 @@ func (o *scribeOptions) Complete() error {
 	if err != nil {
 		return err
 	}
-	scheme := runtime.NewScheme()
-	scribev1alpha1.AddToScheme(scheme)
-	corev1.AddToScheme(scheme)
 	destKClient, err := client.New(destClientConfig, client.Options{Scheme: scheme})
 	if err != nil {
 		return err
@@ -140,6 +177,12 @@ func (o *scribeOptions) Complete() error {
 		return err
 	}
 	o.SourceClient = sourceKClient
+	if o.DestinationClientset, err = kubernetes.NewForConfig(destClientConfig); err != nil {
+		return err
+	}
+	if o.SourceClientset, err = kubernetes.NewForConfig(sourceClientConfig); err != nil {
+		return err
+	}
 	if len(o.destNamespace) == 0 {
 		o.destNamespace, _, err = destf.ToRawKubeConfigLoader().Namespace()
 		if err != nil {
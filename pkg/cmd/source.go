@@ -3,19 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
-	"k8s.io/klog/v2"
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 )
@@ -36,12 +37,27 @@ Scribe is a command line tool for a scribe operator running in a Kubernetes clus
 
         # Create a ReplicationSource for mysql-pvc using Clone copy method in the current namespace.
         $ scribe new-source --source-copy-method Clone --source-pvc mysql-pvc
+
+        # Create a ReplicationSource for mysql-pvc that connects to the ReplicationDestination 'dest-destination'
+        # in namespace 'dest', waiting for it to publish its address and using the secret copied by sync-ssh-secret.
+        $ scribe new-source --source-copy-method Snapshot --source-pvc mysql-pvc \
+            --dest-namespace dest --from-destination dest-destination
+
+        # Create a ReplicationSource for mysql-pvc that syncs to the path 'scribe-mysql' of the rclone remote
+        # 'aws-s3-bucket', defined in the rclone.conf of the secret 'rclone-secret'.
+        $ scribe new-source --source-copy-method Snapshot --source-pvc mysql-pvc \
+            --mover rclone --rclone-config rclone-secret --rclone-config-section aws-s3-bucket --rclone-dest-path scribe-mysql
+
+        # Validate the ReplicationSource against the cluster without persisting it and print it as JSON.
+        $ scribe new-source --source-copy-method Snapshot --source-pvc mysql-pvc \
+            --ssh-keys-secret scribe-rsync-dest-src-dest-destination --dry-run=server -o json
     `)
 )
 
 type sourceOptions struct {
 	scribeOptions                 scribeOptions
 	sshKeysSecretOptions          sshKeysSecretOptions
+	printOptions                  printOptions
 	SourceCopyMethod              string //v1alpha1.CopyMethodType
 	SourceCapacity                string //*resource.Quantity
 	SourceStorageClassName        string
@@ -56,9 +72,14 @@ type sourceOptions struct {
 	SourceServiceType             string //*corev1.ServiceType
 	Port                          int32  //int32
 	Path                          string
+	Mover                         string
 	RcloneConfig                  string
+	RcloneConfigSection           string
+	RcloneDestPath                string
 	Provider                      string
 	ProviderParameters            string //map[string]string
+	FromDestination               string
+	AddressTimeout                time.Duration
 	genericclioptions.IOStreams
 }
 
@@ -85,14 +106,28 @@ func NewCmdScribeNewSource(streams genericclioptions.IOStreams) *cobra.Command {
 
 func NewSourceOptions(streams genericclioptions.IOStreams) *sourceOptions {
 	return &sourceOptions{
-		IOStreams: streams,
+		printOptions: newPrintOptions("created"),
+		IOStreams:    streams,
 	}
 }
 
-func (o *sourceOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) error {
+func (o *sourceOptions) bindFlags(cmd *cobra.Command) {
+	o.bindSourceFlags(cmd)
+	o.printOptions.bindFlags(cmd)
+	o.bindRemoteFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&o.FromDestination, "from-destination", o.FromDestination, "name of the ReplicationDestination in --dest-namespace to replicate to. If --address is not set, wait for the ReplicationDestination to publish its address and use it. If --ssh-keys-secret is not set, use the secret copied by sync-ssh-secret.")
+	flags.DurationVar(&o.AddressTimeout, "address-timeout", 5*time.Minute, "how long to wait for the ReplicationDestination passed with --from-destination to publish its address.")
+	o.bindMoverFlags(cmd)
+	cmd.MarkFlagRequired("source-copy-method")
+	cmd.MarkFlagRequired("source-pvc")
+}
+
+// bindSourceFlags binds the flags that only apply to the ReplicationSource, so that
+// commands also creating a ReplicationDestination can bind them next to the destination flags.
+func (o *sourceOptions) bindSourceFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.StringVar(&o.SourceCopyMethod, "source-copy-method", o.SourceCopyMethod, "the method of creating a point-in-time image of the source volume; one of 'None|Clone|Snapshot'")
-	flags.StringVar(&o.Address, "address", o.Address, "the remote address to connect to for replication.")
 	flags.StringVar(&o.SourceCapacity, "source-capacity", o.SourceCapacity, "provided to override the capacity of the point-in-Time image.")
 	flags.StringVar(&o.SourceStorageClassName, "source-storage-class-name", o.SourceStorageClassName, "provided to override the StorageClass of the point-in-Time image.")
 	flags.StringVar(&o.SourceAccessMode, "source-access-mode", o.SourceAccessMode, "provided to override the accessModes of the point-in-Time image. One of 'ReadWriteOnce|ReadOnlyMany|ReadWriteMany")
@@ -106,6 +141,12 @@ func (o *sourceOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) error {
 	flags.StringVar(&o.SourceServiceType, "source-service-type", o.SourceServiceType, "one of ClusterIP|LoadBalancer. Service type that will be created for incoming SSH connections. (default 'ClusterIP')")
 	// TODO: Defaulted in CLI, should it be??
 	flags.StringVar(&o.SourceName, "source-name", o.SourceName, "name of the ReplicationSource resource (default '<source-ns>-scribe-source')")
+}
+
+// bindRemoteFlags binds the flags describing the rsync connection to the remote side and the external provider.
+func (o *sourceOptions) bindRemoteFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.Address, "address", o.Address, "the remote address to connect to for replication.")
 	// defaults to 22 after creation
 	flags.Int32Var(&o.Port, "port", o.Port, "SSH port to connect to for replication. (default 22)")
 	flags.StringVar(&o.Provider, "provider", o.Provider, "name of an external replication provider, if applicable; pass as 'domain.com/provider'")
@@ -113,29 +154,21 @@ func (o *sourceOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) error {
 	flags.StringVar(&o.ProviderParameters, "provider-parameters", o.ProviderParameters, "provider-specific key/value configuration parameters, if using an external provider; pass as 'key/value,key1/value1,key2/value2'")
 	// defaults to "/" after creation
 	flags.StringVar(&o.Path, "path", o.Path, "the remote path to rsync to (default '/')")
-	cmd.MarkFlagRequired("source-copy-method")
-	cmd.MarkFlagRequired("source-pvc")
-	flags.VisitAll(func(f *pflag.Flag) {
-		if !f.Changed && v.IsSet(f.Name) {
-			val := v.Get(f.Name)
-			flags.Set(f.Name, fmt.Sprintf("%v", val))
-		}
-	})
-	return nil
+}
+
+// bindMoverFlags binds the flags selecting and configuring the data mover.
+func (o *sourceOptions) bindMoverFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.Mover, "mover", "rsync", "the data mover used for replication; one of 'rsync|rclone'")
+	flags.StringVar(&o.RcloneConfig, "rclone-config", o.RcloneConfig, "name of the secret holding the rclone.conf, in the namespace of the ReplicationSource. Required with --mover rclone.")
+	flags.StringVar(&o.RcloneConfigSection, "rclone-config-section", o.RcloneConfigSection, "the section of the rclone.conf defining the remote to sync to. Required with --mover rclone.")
+	flags.StringVar(&o.RcloneDestPath, "rclone-dest-path", o.RcloneDestPath, "the path on the remote to sync to, such as a bucket name. Required with --mover rclone.")
 }
 
 func (o *sourceOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
-	// config file in current directory
-	// TODO: where to look for config file
-	v.SetConfigName(scribeConfig)
-	v.AddConfigPath(".")
-	v.SetConfigType("yaml")
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
-		}
-	}
-	o.bindFlags(cmd, v)
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
 	return nil
 }
 
@@ -144,28 +177,141 @@ func (o *sourceOptions) Complete(cmd *cobra.Command) error {
 	if err != nil {
 		return err
 	}
+	if err := o.printOptions.Complete(cmd); err != nil {
+		return err
+	}
 	o.SourceNamespace = o.scribeOptions.sourceNamespace
 	if len(o.SourceName) == 0 {
 		o.SourceName = o.SourceNamespace + "-source"
 	}
+	if len(o.FromDestination) > 0 {
+		if o.Mover == "rclone" {
+			return fmt.Errorf("--from-destination only applies to --mover rsync")
+		}
+		if err := o.completeFromDestination(); err != nil {
+			return err
+		}
+	}
 	klog.V(2).Infof("replication source %s will be created in %s namespace", o.SourceName, o.SourceNamespace)
 	return nil
 }
 
-// Validate validates ReplicationSource options.
+// completeFromDestination fills in the address and SSH keys secret from the ReplicationDestination
+// passed with --from-destination, waiting for it to publish its address if needed.
+func (o *sourceOptions) completeFromDestination() error {
+	nsName := types.NamespacedName{
+		Namespace: o.scribeOptions.destNamespace,
+		Name:      o.FromDestination,
+	}
+	ready := func(*scribev1alpha1.ReplicationDestination) bool { return true }
+	if len(o.Address) == 0 {
+		klog.V(0).Infof("waiting up to %s for ReplicationDestination %s in namespace %s to publish its address", o.AddressTimeout, nsName.Name, nsName.Namespace)
+		ready = destinationHasAddress
+	}
+	rd, err := waitForDestination(context.TODO(), o.scribeOptions.DestinationClient, nsName, o.AddressTimeout, ready)
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %s waiting for ReplicationDestination %s in namespace %s to publish its address, retry with a longer --address-timeout or pass --address", o.AddressTimeout, nsName.Name, nsName.Namespace)
+	}
+	if err != nil {
+		return err
+	}
+	if len(o.Address) == 0 {
+		o.Address = *rd.Status.Rsync.Address
+		klog.V(2).Infof("using address %s of ReplicationDestination %s", o.Address, rd.Name)
+	}
+	if len(o.sshKeysSecretOptions.SSHKeysSecret) == 0 {
+		o.sshKeysSecretOptions.SSHKeysSecret = destinationSSHKeysSecret(rd)
+		klog.V(2).Infof("using SSH keys secret %s of ReplicationDestination %s", o.sshKeysSecretOptions.SSHKeysSecret, rd.Name)
+	}
+	return nil
+}
+
+// Validate validates ReplicationSource options, then checks them against the source cluster unless the
+// ReplicationSource is only printed with --dry-run=client.
 func (o *sourceOptions) Validate() error {
+	if err := o.validateFlags(); err != nil {
+		return err
+	}
+	if o.printOptions.DryRunStrategy == kcmdutil.DryRunClient {
+		return nil
+	}
+	_, err := o.preflight(context.TODO())
+	return err
+}
+
+// validateFlags validates ReplicationSource options without reading the cluster.
+func (o *sourceOptions) validateFlags() error {
 	if len(o.SourceCopyMethod) == 0 {
 		return fmt.Errorf("must provide --copy-method; one of 'None|Clone|Snapshot'")
 	}
+	if err := validateMover(o.Mover, o.RcloneConfig, o.RcloneConfigSection, o.RcloneDestPath); err != nil {
+		return err
+	}
 	//TODO: FIX THIS
-	if len(o.sshKeysSecretOptions.SSHKeysSecret) == 0 {
+	if len(o.sshKeysSecretOptions.SSHKeysSecret) == 0 && o.Mover != "rclone" {
 		return fmt.Errorf("must provide the name of the secret in ReplicationSource namespace that holds the SSHKeys for connecting to the ReplicationDestination namespace")
 	}
-	return nil
+	return o.validateVolumeFlags()
+}
+
+// validateVolumeFlags validates the capacity and schedule of the ReplicationSource without reading the cluster.
+func (o *sourceOptions) validateVolumeFlags() error {
+	if err := validateCapacity(o.SourceCapacity, "--source-capacity"); err != nil {
+		return err
+	}
+	return validateSchedule(o.SourceSchedule, "--source-cron-spec")
+}
+
+// preflight checks that the source cluster can run the ReplicationSource: the CRD is installed, the
+// namespace, the StorageClass and the VolumeSnapshotClass exist, and --source-pvc is Bound and fits in
+// --source-capacity. It returns the PersistentVolumeClaim passed with --source-pvc.
+func (o *sourceOptions) preflight(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	c := o.scribeOptions.SourceClient
+	kubeContext := o.scribeOptions.sourceKubeContext
+	if err := preflightNamespace(ctx, c, o.SourceNamespace, kubeContext); err != nil {
+		return nil, err
+	}
+	if err := preflightCRD(ctx, c, &scribev1alpha1.ReplicationSourceList{}, "ReplicationSource", o.SourceNamespace, kubeContext); err != nil {
+		return nil, err
+	}
+	pvc, err := preflightPVC(ctx, c, o.SourceNamespace, o.SourcePVC, "--source-pvc", kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	if err := preflightCapacity(o.SourceCapacity, "--source-capacity", pvc); err != nil {
+		return nil, err
+	}
+	if err := preflightStorageClass(ctx, c, o.SourceStorageClassName, "--source-storage-class-name", kubeContext); err != nil {
+		return nil, err
+	}
+	if copyMethod, _ := parseCopyMethod(o.SourceCopyMethod); copyMethod == scribev1alpha1.CopyMethodSnapshot || len(o.SourceVolumeSnapshotClassName) > 0 {
+		if err := preflightSnapshotClass(ctx, c, o.SourceVolumeSnapshotClassName, "--source-volume-snapshot-class", kubeContext); err != nil {
+			return nil, err
+		}
+	}
+	return pvc, nil
 }
 
 // CreateReplicationSource creates a ReplicationSource resource
 func (o *sourceOptions) CreateReplicationSource() error {
+	obj, err := o.newReplicationSource()
+	if err != nil {
+		return err
+	}
+	return o.printOptions.create(context.TODO(), o.scribeOptions.SourceClient, obj, o.Out)
+}
+
+// defaultedFields returns the spec fields newReplicationSource sets to a default because the options do
+// not set them.
+func (o *sourceOptions) defaultedFields() [][]string {
+	if o.Mover != "rclone" && len(o.SourceServiceType) == 0 {
+		return [][]string{{"spec", "rsync", "serviceType"}}
+	}
+	return nil
+}
+
+// newReplicationSource returns the ReplicationSource described by the options.
+func (o *sourceOptions) newReplicationSource() (*scribev1alpha1.ReplicationSource, error) {
 	c := &commonOptions{}
 	switch {
 	case len(o.SourceCapacity) > 0:
@@ -174,41 +320,28 @@ func (o *sourceOptions) CreateReplicationSource() error {
 	default:
 		c.capacity = nil
 	}
-	if o.Port == 0 {
-		c.port = nil
+	if o.Port != 0 {
+		c.port = &o.Port
 	}
-	switch o.SourceCopyMethod {
-	case "None", "none":
-		c.copyMethod = scribev1alpha1.CopyMethodNone
-	case "Clone", "clone":
-		c.copyMethod = scribev1alpha1.CopyMethodClone
-	case "Snapshot", "snapshot", "SnapShot":
-		c.copyMethod = scribev1alpha1.CopyMethodSnapshot
-	default:
-		return fmt.Errorf("unrecognized --dest-copy-method: %s", o.SourceCopyMethod)
+	copyMethod, err := parseCopyMethod(o.SourceCopyMethod)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized --source-copy-method: %s", o.SourceCopyMethod)
 	}
+	c.copyMethod = copyMethod
 	if len(o.SourceAccessMode) > 0 {
-		switch o.SourceAccessMode {
-		case "ReadWriteOnce":
-			c.accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
-		case "ReadWriteMany":
-			c.accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
-		case "ReadOnlyMany":
-			c.accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}
-		default:
-			return fmt.Errorf("unrecognized --dest-access-mode %s", o.SourceAccessMode)
+		accessModes, err := parseAccessMode(o.SourceAccessMode)
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized --source-access-mode %s", o.SourceAccessMode)
 		}
+		c.accessModes = accessModes
 	}
 	switch {
 	case len(o.SourceServiceType) > 0:
-		switch o.SourceServiceType {
-		case "ClusterIP", "clusterip", "clusterIP":
-			c.serviceType = corev1.ServiceTypeClusterIP
-		case "LoadBalancer", "loadbalancer", "Loadbalancer":
-			c.serviceType = corev1.ServiceTypeLoadBalancer
-		default:
-			return fmt.Errorf("unrecognized --dest-service-type %s", o.SourceServiceType)
+		serviceType, err := parseServiceType(o.SourceServiceType)
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized --source-service-type %s", o.SourceServiceType)
 		}
+		c.serviceType = serviceType
 	// if not set, then default to clusterIP
 	default:
 		c.serviceType = corev1.ServiceTypeClusterIP
@@ -238,16 +371,8 @@ func (o *sourceOptions) CreateReplicationSource() error {
 	if len(o.SourcePVC) > 0 {
 		c.pvc = &o.SourcePVC
 	}
-	c.parameters = make(map[string]string)
-	if len(o.ProviderParameters) > 0 {
-		p := strings.Split(o.ProviderParameters, ",")
-		for _, kv := range p {
-			pair := strings.Split(kv, "/")
-			if len(pair) != 2 {
-				return fmt.Errorf("error parsing --provider-parameters %s, must be passed as key/value,key1/value1...", o.ProviderParameters)
-			}
-			c.parameters[pair[0]] = pair[1]
-		}
+	if c.parameters, err = parseProviderParameters(o.ProviderParameters); err != nil {
+		return nil, err
 	}
 	triggerSpec := &scribev1alpha1.ReplicationSourceTriggerSpec{
 		Schedule: &o.SourceSchedule,
@@ -255,20 +380,33 @@ func (o *sourceOptions) CreateReplicationSource() error {
 	if len(o.SourceSchedule) == 0 {
 		triggerSpec = nil
 	}
-	rsyncSpec := &scribev1alpha1.ReplicationSourceRsyncSpec{
-		ReplicationSourceVolumeOptions: scribev1alpha1.ReplicationSourceVolumeOptions{
-			CopyMethod:              c.copyMethod,
-			Capacity:                c.capacity,
-			StorageClassName:        c.storageClassName,
-			AccessModes:             c.accessModes,
-			VolumeSnapshotClassName: c.volumeSnapClassName,
-		},
-		SSHKeys:     c.sshKeysSecret,
-		ServiceType: &c.serviceType,
-		Address:     c.address,
-		Port:        c.port,
-		Path:        c.path,
-		SSHUser:     c.sshUser,
+	volumeOptions := scribev1alpha1.ReplicationSourceVolumeOptions{
+		CopyMethod:              c.copyMethod,
+		Capacity:                c.capacity,
+		StorageClassName:        c.storageClassName,
+		AccessModes:             c.accessModes,
+		VolumeSnapshotClassName: c.volumeSnapClassName,
+	}
+	var rsyncSpec *scribev1alpha1.ReplicationSourceRsyncSpec
+	var rcloneSpec *scribev1alpha1.ReplicationSourceRcloneSpec
+	switch o.Mover {
+	case "rclone":
+		rcloneSpec = &scribev1alpha1.ReplicationSourceRcloneSpec{
+			ReplicationSourceVolumeOptions: volumeOptions,
+			RcloneConfig:                   &o.RcloneConfig,
+			RcloneConfigSection:            &o.RcloneConfigSection,
+			RcloneDestPath:                 &o.RcloneDestPath,
+		}
+	default:
+		rsyncSpec = &scribev1alpha1.ReplicationSourceRsyncSpec{
+			ReplicationSourceVolumeOptions: volumeOptions,
+			SSHKeys:                        c.sshKeysSecret,
+			ServiceType:                    &c.serviceType,
+			Address:                        c.address,
+			Port:                           c.port,
+			Path:                           c.path,
+			SSHUser:                        c.sshUser,
+		}
 	}
 	var externalSpec *scribev1alpha1.ReplicationSourceExternalSpec
 	if len(o.Provider) > 0 && c.parameters != nil {
@@ -290,12 +428,9 @@ func (o *sourceOptions) CreateReplicationSource() error {
 			SourcePVC: *c.pvc,
 			Trigger:   triggerSpec,
 			Rsync:     rsyncSpec,
+			Rclone:    rcloneSpec,
 			External:  externalSpec,
 		},
 	}
-	if err := o.scribeOptions.SourceClient.Create(context.TODO(), rs); err != nil {
-		return err
-	}
-	klog.V(0).Infof("ReplicationSource %s created in namespace %s", o.SourceName, o.SourceNamespace)
-	return nil
+	return rs, nil
 }
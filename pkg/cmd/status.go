@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/operator-framework/operator-lib/status"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/describe"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeStatusLong = templates.LongDesc(`
+Report whether a replication pair is healthy: the last sync of the ReplicationSource and the
+ReplicationDestination, the time since then compared to the sync schedule, the latest image and the
+conditions that are not met.
+
+The pair is stale when the ReplicationSource missed a whole scheduled sync, that is when the second
+scheduled time after its last sync has passed, plus the duration of the last sync. The schedule is the one
+passed with --source-cron-spec, or the schedule of the ReplicationSource. With --max-lag, the pair is
+stale when the last sync is older than the given duration instead.
+
+The command exits with a non-zero status when the pair is stale or a condition is failing.
+`)
+	scribeStatusExample = templates.Examples(`
+        # Report the status of the ReplicationDestination 'dest-destination' and its ReplicationSource.
+        scribe status dest-destination --dest-namespace dest --source-namespace source
+
+        # Fail when the last sync of the pair is older than 15 minutes.
+        scribe status dest-destination --dest-namespace dest --source-namespace source --max-lag 15m || alert
+    `)
+)
+
+type statusOptions struct {
+	scribeOptions  scribeOptions
+	pairOptions    pairOptions
+	SourceSchedule string
+	MaxLag         time.Duration
+	genericclioptions.IOStreams
+}
+
+func NewStatusOptions(streams genericclioptions.IOStreams) *statusOptions {
+	return &statusOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeStatus(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewStatusOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "status [NAME] [OPTIONS]",
+		Short:   i18n.T("Report the sync lag and health of a replication pair."),
+		Long:    fmt.Sprintf(scribeStatusLong),
+		Example: fmt.Sprintf(scribeStatusExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Status())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *statusOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&o.SourceSchedule, "source-cron-spec", o.SourceSchedule, "cronspec the ReplicationSource is expected to sync on. If not set, the schedule of the ReplicationSource is used.")
+	flags.DurationVar(&o.MaxLag, "max-lag", o.MaxLag, "if set, the pair is stale when its last sync is older than this duration, whatever the schedule.")
+}
+
+func (o *statusOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *statusOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// Validate validates status options.
+func (o *statusOptions) Validate() error {
+	if len(o.SourceSchedule) > 0 {
+		if _, err := cron.ParseStandard(o.SourceSchedule); err != nil {
+			return fmt.Errorf("invalid --source-cron-spec %s: %v", o.SourceSchedule, err)
+		}
+	}
+	return nil
+}
+
+// Status prints the health of the pair and returns an error if it is unhealthy.
+func (o *statusOptions) Status() error {
+	ctx := context.Background()
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	problems := o.pairProblems(pair, time.Now())
+
+	out := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	w := describe.NewPrefixWriter(out)
+	rd := pair.Destination
+	w.Write(describe.LEVEL_0, "ReplicationDestination:\t%s/%s\n", rd.Namespace, rd.Name)
+	var lastSyncTime, nextSyncTime *metav1.Time
+	latestImage := "<none>"
+	if rd.Status != nil {
+		lastSyncTime, nextSyncTime = rd.Status.LastSyncTime, rd.Status.NextSyncTime
+		if rd.Status.LatestImage != nil {
+			latestImage = rd.Status.LatestImage.Kind + "/" + rd.Status.LatestImage.Name
+		}
+	}
+	w.Write(describe.LEVEL_1, "Last Sync:\t%s\n", timeString(lastSyncTime))
+	w.Write(describe.LEVEL_1, "Next Sync:\t%s\n", timeString(nextSyncTime))
+	w.Write(describe.LEVEL_1, "Latest Image:\t%s\n", latestImage)
+	if rs := pair.Source; rs != nil {
+		w.Write(describe.LEVEL_0, "ReplicationSource:\t%s/%s\n", rs.Namespace, rs.Name)
+		lastSyncTime, nextSyncTime = nil, nil
+		if rs.Status != nil {
+			lastSyncTime, nextSyncTime = rs.Status.LastSyncTime, rs.Status.NextSyncTime
+		}
+		w.Write(describe.LEVEL_1, "Last Sync:\t%s\n", timeString(lastSyncTime))
+		w.Write(describe.LEVEL_1, "Next Sync:\t%s\n", timeString(nextSyncTime))
+		w.Write(describe.LEVEL_1, "Schedule:\t%s\n", valueOrNone(o.sourceSchedule(rs)))
+	}
+	if len(problems) == 0 {
+		w.Write(describe.LEVEL_0, "Health:\tHealthy\n")
+		return out.Flush()
+	}
+	w.Write(describe.LEVEL_0, "Health:\tUnhealthy\n")
+	for _, problem := range problems {
+		w.Write(describe.LEVEL_1, "- %s\n", problem)
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return fmt.Errorf("replication to ReplicationDestination %s is unhealthy: %s", rd.Name, strings.Join(problems, "; "))
+}
+
+// pairProblems returns why the pair is unhealthy at the given time, if it is.
+func (o *statusOptions) pairProblems(pair *replicationPair, now time.Time) []string {
+	problems := []string{}
+	rs := pair.Source
+	if rs == nil {
+		return append(problems, "no ReplicationSource replicates to the ReplicationDestination")
+	}
+	if rs.Spec.Paused {
+		problems = append(problems, "ReplicationSource is paused")
+	}
+	if pair.Destination.Spec.Paused {
+		problems = append(problems, "ReplicationDestination is paused")
+	}
+	// a pair that never synced is compared to its creation
+	lastSync := rs.CreationTimestamp.Time
+	var lastSyncDuration time.Duration
+	if rs.Status != nil && rs.Status.LastSyncTime != nil {
+		lastSync = rs.Status.LastSyncTime.Time
+		if rs.Status.LastSyncDuration != nil {
+			lastSyncDuration = rs.Status.LastSyncDuration.Duration
+		}
+	}
+	if stale, reason := o.isStale(rs, lastSync, lastSyncDuration, now); stale {
+		problems = append(problems, reason)
+	}
+	if rs.Status != nil {
+		problems = append(problems, failingConditions("ReplicationSource", rs.Status.Conditions)...)
+	}
+	if pair.Destination.Status != nil {
+		problems = append(problems, failingConditions("ReplicationDestination", pair.Destination.Status.Conditions)...)
+	}
+	return problems
+}
+
+// isStale compares the time of the last sync to --max-lag or to the schedule.
+func (o *statusOptions) isStale(rs *scribev1alpha1.ReplicationSource, lastSync time.Time, lastSyncDuration time.Duration, now time.Time) (bool, string) {
+	lag := duration.HumanDuration(now.Sub(lastSync))
+	if o.MaxLag > 0 {
+		if now.Sub(lastSync) > o.MaxLag {
+			return true, fmt.Sprintf("last sync %s ago, more than --max-lag %s", lag, o.MaxLag)
+		}
+		return false, ""
+	}
+	schedule := o.sourceSchedule(rs)
+	if schedule == nil {
+		// continuous replication has no expected sync time
+		return false, ""
+	}
+	sched, err := cron.ParseStandard(*schedule)
+	if err != nil {
+		return true, fmt.Sprintf("invalid schedule %s: %v", *schedule, err)
+	}
+	missed := sched.Next(sched.Next(lastSync)).Add(lastSyncDuration)
+	if now.After(missed) {
+		return true, fmt.Sprintf("last sync %s ago, a sync scheduled with %q was missed", lag, *schedule)
+	}
+	return false, ""
+}
+
+// sourceSchedule returns the schedule the ReplicationSource is expected to sync on.
+func (o *statusOptions) sourceSchedule(rs *scribev1alpha1.ReplicationSource) *string {
+	if len(o.SourceSchedule) > 0 {
+		return &o.SourceSchedule
+	}
+	if rs.Spec.Trigger != nil && rs.Spec.Trigger.Schedule != nil && len(*rs.Spec.Trigger.Schedule) > 0 {
+		return rs.Spec.Trigger.Schedule
+	}
+	return nil
+}
+
+// failingConditions returns the conditions of the object that are not met.
+func failingConditions(kind string, conditions status.Conditions) []string {
+	failing := []string{}
+	for _, c := range conditions {
+		if c.Status == corev1.ConditionFalse {
+			failing = append(failing, fmt.Sprintf("%s condition %s is False: %s: %s", kind, c.Type, c.Reason, c.Message))
+		}
+	}
+	return failing
+}
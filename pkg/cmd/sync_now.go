@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeSyncNowLong = templates.LongDesc(`
+Force an immediate sync of a replication pair. The schedules of the ReplicationSource and of the
+ReplicationDestination are removed so that they sync right away, and put back once the ReplicationSource,
+then the ReplicationDestination, complete a sync. The schedules are put back as well when the command
+times out or is interrupted.
+
+A pair paused with pause must be resumed first.
+`)
+	scribeSyncNowExample = templates.Examples(`
+        # Sync the ReplicationDestination 'dest-destination' and its ReplicationSource before a migration.
+        scribe sync-now dest-destination --dest-namespace dest --source-namespace source
+
+        # Sync and restore the result.
+        scribe sync-now dest-destination --dest-namespace dest --source-namespace source && \
+            scribe restore dest-destination --dest-namespace dest --restore-pvc mysql-pv-claim --replace
+    `)
+)
+
+type syncNowOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	Timeout       time.Duration
+	genericclioptions.IOStreams
+}
+
+func NewSyncNowOptions(streams genericclioptions.IOStreams) *syncNowOptions {
+	return &syncNowOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeSyncNow(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewSyncNowOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "sync-now [NAME] [OPTIONS]",
+		Short:   i18n.T("Sync a replication pair immediately, outside of its schedule."),
+		Long:    fmt.Sprintf(scribeSyncNowLong),
+		Example: fmt.Sprintf(scribeSyncNowExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.SyncNow())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *syncNowOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.DurationVar(&o.Timeout, "timeout", 30*time.Minute, "how long to wait for the sync to complete.")
+}
+
+func (o *syncNowOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *syncNowOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// SyncNow removes the schedules of the pair, waits for a sync and puts the schedules back.
+func (o *syncNowOptions) SyncNow() (err error) {
+	// the schedules are put back on interrupt as well
+	ictx, icancel := interruptContext()
+	defer icancel()
+	ctx, cancel := context.WithTimeout(ictx, o.Timeout)
+	defer cancel()
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	rd, rs := pair.Destination, pair.Source
+	rdName := types.NamespacedName{Namespace: rd.Namespace, Name: rd.Name}
+	if rs == nil {
+		return fmt.Errorf("no ReplicationSource in namespace %s replicates to ReplicationDestination %s, pass --source-name", o.scribeOptions.sourceNamespace, rd.Name)
+	}
+	rsName := types.NamespacedName{Namespace: rs.Namespace, Name: rs.Name}
+	if rs.Spec.Paused || rd.Spec.Paused {
+		return fmt.Errorf("replication to ReplicationDestination %s is paused, resume it with scribe resume before syncing", rd.Name)
+	}
+
+	// sync times are stored with a precision of one second
+	start := time.Now().Truncate(time.Second)
+	if rs.Spec.Trigger != nil {
+		trigger := rs.Spec.Trigger
+		if err := setSourceTrigger(ctx, o.scribeOptions.SourceClient, rsName, nil); err != nil {
+			return err
+		}
+		klog.V(0).Infof("removed the schedule of ReplicationSource %s", rs.Name)
+		defer func() {
+			if restoreErr := setSourceTrigger(context.Background(), o.scribeOptions.SourceClient, rsName, trigger); restoreErr != nil {
+				klog.Errorf("unable to put back the schedule of ReplicationSource %s: %v", rs.Name, restoreErr)
+				err = restoreErr
+				return
+			}
+			klog.V(0).Infof("put back the schedule of ReplicationSource %s", rs.Name)
+		}()
+	}
+	if rd.Spec.Trigger != nil {
+		trigger := rd.Spec.Trigger
+		if err := setDestinationTrigger(ctx, o.scribeOptions.DestinationClient, rdName, nil); err != nil {
+			return err
+		}
+		klog.V(0).Infof("removed the schedule of ReplicationDestination %s", rd.Name)
+		defer func() {
+			if restoreErr := setDestinationTrigger(context.Background(), o.scribeOptions.DestinationClient, rdName, trigger); restoreErr != nil {
+				klog.Errorf("unable to put back the schedule of ReplicationDestination %s: %v", rd.Name, restoreErr)
+				err = restoreErr
+				return
+			}
+			klog.V(0).Infof("put back the schedule of ReplicationDestination %s", rd.Name)
+		}()
+	}
+
+	klog.V(0).Infof("waiting up to %s for ReplicationSource %s to complete a sync", o.Timeout, rs.Name)
+	rs, err = pollSource(ctx, o.scribeOptions.SourceClient, rsName, func(rs *scribev1alpha1.ReplicationSource) bool {
+		return rs.Status != nil && syncedAfter(rs.Status.LastSyncTime, start)
+	})
+	if err != nil {
+		return o.waitError(ctx, err, "ReplicationSource", rsName.Name)
+	}
+	sourceSync := rs.Status.LastSyncTime.Time
+	klog.V(0).Infof("ReplicationSource %s synced at %s, waiting for ReplicationDestination %s", rs.Name, sourceSync.Format(time.RFC3339), rd.Name)
+	rd, err = pollDestination(ctx, o.scribeOptions.DestinationClient, rdName, func(rd *scribev1alpha1.ReplicationDestination) bool {
+		// the destination completes its sync once the source data is received
+		return rd.Status != nil && rd.Status.LastSyncTime != nil && !rd.Status.LastSyncTime.Time.Before(sourceSync)
+	})
+	if err != nil {
+		return o.waitError(ctx, err, "ReplicationDestination", rdName.Name)
+	}
+	klog.V(0).Infof("ReplicationDestination %s synced at %s", rd.Name, rd.Status.LastSyncTime.Format(time.RFC3339))
+	return nil
+}
+
+// waitError tells a timeout from an interruption while waiting for a sync.
+func (o *syncNowOptions) waitError(ctx context.Context, err error, kind, name string) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("timed out after %s waiting for %s %s to complete a sync", o.Timeout, kind, name)
+	case context.Canceled:
+		return fmt.Errorf("interrupted while waiting for %s %s to complete a sync", kind, name)
+	}
+	return err
+}
+
+// setSourceTrigger patches the trigger of the ReplicationSource.
+func setSourceTrigger(ctx context.Context, c client.Client, nsName types.NamespacedName, trigger *scribev1alpha1.ReplicationSourceTriggerSpec) error {
+	rs := &scribev1alpha1.ReplicationSource{}
+	if err := c.Get(ctx, nsName, rs); err != nil {
+		return err
+	}
+	patched := rs.DeepCopy()
+	patched.Spec.Trigger = trigger
+	return c.Patch(ctx, patched, client.MergeFrom(rs))
+}
+
+// setDestinationTrigger patches the trigger of the ReplicationDestination.
+func setDestinationTrigger(ctx context.Context, c client.Client, nsName types.NamespacedName, trigger *scribev1alpha1.ReplicationDestinationTriggerSpec) error {
+	rd := &scribev1alpha1.ReplicationDestination{}
+	if err := c.Get(ctx, nsName, rd); err != nil {
+		return err
+	}
+	patched := rd.DeepCopy()
+	patched.Spec.Trigger = trigger
+	return c.Patch(ctx, patched, client.MergeFrom(rd))
+}
@@ -6,9 +6,9 @@ import (
 
 	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -66,30 +66,15 @@ func NewCmdScribeSyncSSHSecret(streams genericclioptions.IOStreams) *cobra.Comma
 }
 
 func (o *sshKeysSecretOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
-	// config file in current directory
-	// TODO: where to look for config file
-	v.SetConfigName(scribeConfig)
-	v.AddConfigPath(".")
-	v.SetConfigType("yaml")
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
-		}
-	}
-	o.bindFlags(cmd, v)
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
 	return nil
 }
 
-func (o *sshKeysSecretOptions) bindFlags(cmd *cobra.Command, v *viper.Viper) {
+func (o *sshKeysSecretOptions) bindFlags(cmd *cobra.Command) {
 	flags := cmd.Flags()
 	flags.StringVar(&o.SSHKeysSecret, "ssh-keys-secret", o.SSHKeysSecret, "name of an existing valid SSHKeys secret to be used for authentication. If not set, the default SSHKey secret-name will be used from the ReplicationDestination location (default '<scribe-rsync->dest-src-<name-of-replication-destination>)'.")
-
-	flags.VisitAll(func(f *pflag.Flag) {
-		if !f.Changed && v.IsSet(f.Name) {
-			val := v.Get(f.Name)
-			flags.Set(f.Name, fmt.Sprintf("%v", val))
-		}
-	})
 }
 
 func NewSSHKeysSecretOptions(streams genericclioptions.IOStreams) *sshKeysSecretOptions {
@@ -146,3 +131,26 @@ func (o *sshKeysSecretOptions) SyncSSHSecret() error {
 	klog.Infof("secret %s created in namespace %s", o.SSHKeysSecret, o.scribeOptions.sourceNamespace)
 	return nil
 }
+
+// syncSSHSecretIfMissing copies the SSH keys secret to the source namespace unless it already exists there,
+// and returns true if it was copied.
+func (o *sshKeysSecretOptions) syncSSHSecretIfMissing(ctx context.Context) (bool, error) {
+	secret := &corev1.Secret{}
+	nsName := types.NamespacedName{
+		Namespace: o.scribeOptions.sourceNamespace,
+		Name:      o.SSHKeysSecret,
+	}
+	err := o.scribeOptions.SourceClient.Get(ctx, nsName, secret)
+	switch {
+	case err == nil:
+		// same namespace and cluster as the destination, or synced before
+		klog.V(0).Infof("secret %s already exists in namespace %s", nsName.Name, nsName.Namespace)
+		return false, nil
+	case !kerrors.IsNotFound(err):
+		return false, err
+	}
+	if err := o.SyncSSHSecret(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,576 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeUpdateDestinationLong = templates.LongDesc(`
+Update an existing ReplicationDestination with the flags of new-destination. Only the flags passed on the
+command line are applied, as a merge patch: the defaults of new-destination are not, and the scribe-config
+file only selects the kube context and namespace. Passing an empty value, such as --dest-cron-spec "",
+removes the field.
+
+A diff of the changes is printed before they are applied. The schedule of a paused ReplicationDestination
+is recorded to be put back by resume instead of being applied right away.
+`)
+	scribeUpdateDestinationExample = templates.Examples(`
+        # Sync the ReplicationDestination 'dest-destination' every 10 minutes.
+        scribe update-destination dest-destination --dest-namespace dest --dest-cron-spec "*/10 * * * *"
+
+        # Show the change of the StorageClass of the destination volume without applying it.
+        scribe update-destination dest-destination --dest-namespace dest --dest-storage-class-name fast --dry-run=client
+    `)
+	scribeUpdateSourceLong = templates.LongDesc(`
+Update an existing ReplicationSource with the flags of new-source. Only the flags passed on the command
+line are applied, as a merge patch: the defaults of new-source are not, and the scribe-config file only
+selects the kube context and namespace. Passing an empty value, such as --source-cron-spec "", removes
+the field.
+
+A diff of the changes is printed before they are applied. The schedule of a paused ReplicationSource is
+recorded to be put back by resume instead of being applied right away.
+`)
+	scribeUpdateSourceExample = templates.Examples(`
+        # Capture the source volume every hour instead.
+        scribe update-source source-source --source-namespace source --source-cron-spec "0 * * * *"
+
+        # Connect to a new address of the ReplicationDestination and print the updated ReplicationSource.
+        scribe update-source source-source --source-namespace source --address 10.0.0.12 -o yaml
+    `)
+)
+
+// updateFlags tells which of the flags changing the spec were passed to an update command.
+type updateFlags struct {
+	flags     *pflag.FlagSet
+	specFlags []string
+}
+
+// bindSpecFlags calls bind to bind the flags changing the spec and keeps their names.
+func (u *updateFlags) bindSpecFlags(cmd *cobra.Command, bind func()) {
+	u.flags = cmd.Flags()
+	bound := map[string]bool{}
+	u.flags.VisitAll(func(f *pflag.Flag) {
+		bound[f.Name] = true
+	})
+	bind()
+	u.flags.VisitAll(func(f *pflag.Flag) {
+		if !bound[f.Name] {
+			u.specFlags = append(u.specFlags, f.Name)
+		}
+	})
+}
+
+func (u *updateFlags) changed(name string) bool {
+	return u.flags.Changed(name)
+}
+
+// require returns an error if one of the flags was passed for an object that does not have the spec they update.
+func (u *updateFlags) require(present bool, spec string, names ...string) error {
+	if present {
+		return nil
+	}
+	for _, name := range names {
+		if u.changed(name) {
+			return fmt.Errorf("--%s does not apply, the object has no %s spec", name, spec)
+		}
+	}
+	return nil
+}
+
+// anyChanged returns true if one of the flags changing the spec, other than the one selecting the
+// object by name, was passed.
+func (u *updateFlags) anyChanged(nameFlag string) bool {
+	for _, name := range u.specFlags {
+		if name != nameFlag && u.changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+type updateDestinationOptions struct {
+	destinationOptions *destinationOptions
+	updateFlags
+}
+
+func NewUpdateDestinationOptions(streams genericclioptions.IOStreams) *updateDestinationOptions {
+	o := &updateDestinationOptions{
+		destinationOptions: NewDestinationOptions(streams),
+	}
+	o.destinationOptions.printOptions = newPrintOptions("configured")
+	return o
+}
+
+func NewCmdScribeUpdateDestination(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewUpdateDestinationOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "update-destination [NAME] [OPTIONS]",
+		Short:   i18n.T("Update the spec of an existing ReplicationDestination with the flags of new-destination."),
+		Long:    fmt.Sprintf(scribeUpdateDestinationLong),
+		Example: fmt.Sprintf(scribeUpdateDestinationExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.UpdateReplicationDestination())
+		},
+	}
+	kcmdutil.CheckErr(o.destinationOptions.scribeOptions.Bind(cmd, v))
+	o.bindFlags(cmd)
+
+	return cmd
+}
+
+// bindFlags binds the flags of new-destination. They are not read from the scribe-config, so that
+// only the flags passed are applied.
+func (o *updateDestinationOptions) bindFlags(cmd *cobra.Command) {
+	d := o.destinationOptions
+	o.bindSpecFlags(cmd, func() {
+		d.bindDestinationFlags(cmd)
+		d.bindRemoteFlags(cmd)
+		d.bindMoverFlags(cmd)
+		flags := cmd.Flags()
+		flags.StringVar(&d.sshKeysSecretOptions.SSHKeysSecret, "ssh-keys-secret", d.sshKeysSecretOptions.SSHKeysSecret, "name of the secret holding the SSH keys of the rsync connection.")
+	})
+	d.printOptions.bindFlags(cmd)
+}
+
+func (o *updateDestinationOptions) Complete(cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.destinationOptions.DestName = args[0]
+	default:
+		return kcmdutil.UsageErrorf(cmd, "expected the name of one ReplicationDestination, got %d arguments", len(args))
+	}
+	return o.destinationOptions.Complete(cmd)
+}
+
+// Validate validates update-destination options.
+func (o *updateDestinationOptions) Validate() error {
+	if o.changed("mover") {
+		return fmt.Errorf("the mover of a ReplicationDestination cannot be updated, delete and create it again")
+	}
+	if !o.anyChanged("dest-name") {
+		return fmt.Errorf("nothing to update, pass the flags of new-destination to change")
+	}
+	return nil
+}
+
+// UpdateReplicationDestination applies the flags passed to the ReplicationDestination.
+func (o *updateDestinationOptions) UpdateReplicationDestination() error {
+	ctx := context.Background()
+	d := o.destinationOptions
+	rd := &scribev1alpha1.ReplicationDestination{}
+	nsName := types.NamespacedName{Namespace: d.DestNamespace, Name: d.DestName}
+	if err := d.scribeOptions.DestinationClient.Get(ctx, nsName, rd); err != nil {
+		return err
+	}
+	updated := rd.DeepCopy()
+	if err := o.applyFlags(updated); err != nil {
+		return err
+	}
+	return applyUpdate(ctx, d.scribeOptions.DestinationClient, &d.printOptions, "replicationdestination/"+rd.Name, updated, rd, d.Out, d.ErrOut)
+}
+
+// applyFlags sets the fields of the ReplicationDestination from the flags passed.
+func (o *updateDestinationOptions) applyFlags(rd *scribev1alpha1.ReplicationDestination) error {
+	d := o.destinationOptions
+	spec := &rd.Spec
+	var volumeOptions *scribev1alpha1.ReplicationDestinationVolumeOptions
+	switch {
+	case spec.Rsync != nil:
+		volumeOptions = &spec.Rsync.ReplicationDestinationVolumeOptions
+	case spec.Rclone != nil:
+		volumeOptions = &spec.Rclone.ReplicationDestinationVolumeOptions
+	}
+	if err := o.require(volumeOptions != nil, "rsync or rclone", "dest-copy-method", "dest-capacity", "dest-storage-class-name", "dest-access-mode", "dest-volume-snapshot-class", "dest-pvc"); err != nil {
+		return err
+	}
+	if err := o.require(spec.Rsync != nil, "rsync", "address", "port", "path", "dest-ssh-user", "dest-service-type", "ssh-keys-secret"); err != nil {
+		return err
+	}
+	if err := o.require(spec.Rclone != nil, "rclone", "rclone-config", "rclone-config-section", "rclone-dest-path"); err != nil {
+		return err
+	}
+	if o.changed("dest-copy-method") {
+		copyMethod, err := parseCopyMethod(d.DestCopyMethod)
+		if err != nil {
+			return fmt.Errorf("unrecognized --dest-copy-method: %s", d.DestCopyMethod)
+		}
+		volumeOptions.CopyMethod = copyMethod
+	}
+	if o.changed("dest-capacity") {
+		capacity, err := optionalQuantity(d.DestCapacity)
+		if err != nil {
+			return fmt.Errorf("invalid --dest-capacity %s: %v", d.DestCapacity, err)
+		}
+		volumeOptions.Capacity = capacity
+	}
+	if o.changed("dest-storage-class-name") {
+		volumeOptions.StorageClassName = optionalString(d.DestStorageClassName)
+	}
+	if o.changed("dest-access-mode") {
+		accessModes, err := optionalAccessModes(d.DestAccessMode)
+		if err != nil {
+			return fmt.Errorf("unrecognized --dest-access-mode %s", d.DestAccessMode)
+		}
+		volumeOptions.AccessModes = accessModes
+	}
+	if o.changed("dest-volume-snapshot-class") {
+		volumeOptions.VolumeSnapshotClassName = optionalString(d.DestVolumeSnapshotClassName)
+	}
+	if o.changed("dest-pvc") {
+		volumeOptions.DestinationPVC = optionalString(d.DestPVC)
+	}
+	if o.changed("dest-cron-spec") {
+		if _, paused := rd.Annotations[pausedScheduleAnnotation]; paused {
+			rd.Annotations[pausedScheduleAnnotation] = d.DestSchedule
+		} else {
+			spec.Trigger = nil
+			if len(d.DestSchedule) > 0 {
+				spec.Trigger = &scribev1alpha1.ReplicationDestinationTriggerSpec{Schedule: optionalString(d.DestSchedule)}
+			}
+		}
+	}
+	if spec.Rsync != nil {
+		if o.changed("address") {
+			spec.Rsync.Address = optionalString(d.Address)
+		}
+		if o.changed("port") {
+			spec.Rsync.Port = optionalPort(d.Port)
+		}
+		if o.changed("path") {
+			spec.Rsync.Path = optionalString(d.Path)
+		}
+		if o.changed("dest-ssh-user") {
+			spec.Rsync.SSHUser = optionalString(d.SSHUser)
+		}
+		if o.changed("dest-service-type") {
+			serviceType, err := optionalServiceType(d.DestServiceType)
+			if err != nil {
+				return fmt.Errorf("unrecognized --dest-service-type %s", d.DestServiceType)
+			}
+			spec.Rsync.ServiceType = serviceType
+		}
+		if o.changed("ssh-keys-secret") {
+			spec.Rsync.SSHKeys = optionalString(d.sshKeysSecretOptions.SSHKeysSecret)
+		}
+	}
+	if spec.Rclone != nil {
+		if o.changed("rclone-config") {
+			spec.Rclone.RcloneConfig = optionalString(d.RcloneConfig)
+		}
+		if o.changed("rclone-config-section") {
+			spec.Rclone.RcloneConfigSection = optionalString(d.RcloneConfigSection)
+		}
+		if o.changed("rclone-dest-path") {
+			spec.Rclone.RcloneDestPath = optionalString(d.RcloneDestPath)
+		}
+	}
+	if o.changed("provider") || o.changed("provider-parameters") {
+		if spec.External == nil {
+			spec.External = &scribev1alpha1.ReplicationDestinationExternalSpec{}
+		}
+		if o.changed("provider") {
+			spec.External.Provider = d.Provider
+		}
+		if o.changed("provider-parameters") {
+			parameters, err := parseProviderParameters(d.ProviderParameters)
+			if err != nil {
+				return err
+			}
+			spec.External.Parameters = parameters
+		}
+		if len(spec.External.Provider) == 0 {
+			spec.External = nil
+		}
+	}
+	return nil
+}
+
+type updateSourceOptions struct {
+	sourceOptions *sourceOptions
+	updateFlags
+}
+
+func NewUpdateSourceOptions(streams genericclioptions.IOStreams) *updateSourceOptions {
+	o := &updateSourceOptions{
+		sourceOptions: NewSourceOptions(streams),
+	}
+	o.sourceOptions.printOptions = newPrintOptions("configured")
+	return o
+}
+
+func NewCmdScribeUpdateSource(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewUpdateSourceOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "update-source [NAME] [OPTIONS]",
+		Short:   i18n.T("Update the spec of an existing ReplicationSource with the flags of new-source."),
+		Long:    fmt.Sprintf(scribeUpdateSourceLong),
+		Example: fmt.Sprintf(scribeUpdateSourceExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.UpdateReplicationSource())
+		},
+	}
+	kcmdutil.CheckErr(o.sourceOptions.scribeOptions.Bind(cmd, v))
+	o.bindFlags(cmd)
+
+	return cmd
+}
+
+// bindFlags binds the flags of new-source. They are not read from the scribe-config, so that only
+// the flags passed are applied.
+func (o *updateSourceOptions) bindFlags(cmd *cobra.Command) {
+	s := o.sourceOptions
+	o.bindSpecFlags(cmd, func() {
+		s.bindSourceFlags(cmd)
+		s.bindRemoteFlags(cmd)
+		s.bindMoverFlags(cmd)
+		flags := cmd.Flags()
+		flags.StringVar(&s.sshKeysSecretOptions.SSHKeysSecret, "ssh-keys-secret", s.sshKeysSecretOptions.SSHKeysSecret, "name of the secret holding the SSH keys of the rsync connection.")
+	})
+	s.printOptions.bindFlags(cmd)
+}
+
+func (o *updateSourceOptions) Complete(cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.sourceOptions.SourceName = args[0]
+	default:
+		return kcmdutil.UsageErrorf(cmd, "expected the name of one ReplicationSource, got %d arguments", len(args))
+	}
+	return o.sourceOptions.Complete(cmd)
+}
+
+// Validate validates update-source options.
+func (o *updateSourceOptions) Validate() error {
+	if o.changed("mover") {
+		return fmt.Errorf("the mover of a ReplicationSource cannot be updated, delete and create it again")
+	}
+	if o.changed("source-pvc") && len(o.sourceOptions.SourcePVC) == 0 {
+		return fmt.Errorf("--source-pvc cannot be removed, a ReplicationSource replicates a PersistentVolumeClaim")
+	}
+	if !o.anyChanged("source-name") {
+		return fmt.Errorf("nothing to update, pass the flags of new-source to change")
+	}
+	return nil
+}
+
+// UpdateReplicationSource applies the flags passed to the ReplicationSource.
+func (o *updateSourceOptions) UpdateReplicationSource() error {
+	ctx := context.Background()
+	s := o.sourceOptions
+	rs := &scribev1alpha1.ReplicationSource{}
+	nsName := types.NamespacedName{Namespace: s.SourceNamespace, Name: s.SourceName}
+	if err := s.scribeOptions.SourceClient.Get(ctx, nsName, rs); err != nil {
+		return err
+	}
+	updated := rs.DeepCopy()
+	if err := o.applyFlags(updated); err != nil {
+		return err
+	}
+	return applyUpdate(ctx, s.scribeOptions.SourceClient, &s.printOptions, "replicationsource/"+rs.Name, updated, rs, s.Out, s.ErrOut)
+}
+
+// applyFlags sets the fields of the ReplicationSource from the flags passed.
+func (o *updateSourceOptions) applyFlags(rs *scribev1alpha1.ReplicationSource) error {
+	s := o.sourceOptions
+	spec := &rs.Spec
+	var volumeOptions *scribev1alpha1.ReplicationSourceVolumeOptions
+	switch {
+	case spec.Rsync != nil:
+		volumeOptions = &spec.Rsync.ReplicationSourceVolumeOptions
+	case spec.Rclone != nil:
+		volumeOptions = &spec.Rclone.ReplicationSourceVolumeOptions
+	}
+	if err := o.require(volumeOptions != nil, "rsync or rclone", "source-copy-method", "source-capacity", "source-storage-class-name", "source-access-mode", "source-volume-snapshot-class"); err != nil {
+		return err
+	}
+	if err := o.require(spec.Rsync != nil, "rsync", "address", "port", "path", "source-ssh-user", "source-service-type", "ssh-keys-secret"); err != nil {
+		return err
+	}
+	if err := o.require(spec.Rclone != nil, "rclone", "rclone-config", "rclone-config-section", "rclone-dest-path"); err != nil {
+		return err
+	}
+	if o.changed("source-pvc") {
+		spec.SourcePVC = s.SourcePVC
+	}
+	if o.changed("source-copy-method") {
+		copyMethod, err := parseCopyMethod(s.SourceCopyMethod)
+		if err != nil {
+			return fmt.Errorf("unrecognized --source-copy-method: %s", s.SourceCopyMethod)
+		}
+		volumeOptions.CopyMethod = copyMethod
+	}
+	if o.changed("source-capacity") {
+		capacity, err := optionalQuantity(s.SourceCapacity)
+		if err != nil {
+			return fmt.Errorf("invalid --source-capacity %s: %v", s.SourceCapacity, err)
+		}
+		volumeOptions.Capacity = capacity
+	}
+	if o.changed("source-storage-class-name") {
+		volumeOptions.StorageClassName = optionalString(s.SourceStorageClassName)
+	}
+	if o.changed("source-access-mode") {
+		accessModes, err := optionalAccessModes(s.SourceAccessMode)
+		if err != nil {
+			return fmt.Errorf("unrecognized --source-access-mode %s", s.SourceAccessMode)
+		}
+		volumeOptions.AccessModes = accessModes
+	}
+	if o.changed("source-volume-snapshot-class") {
+		volumeOptions.VolumeSnapshotClassName = optionalString(s.SourceVolumeSnapshotClassName)
+	}
+	if o.changed("source-cron-spec") {
+		if _, paused := rs.Annotations[pausedScheduleAnnotation]; paused {
+			rs.Annotations[pausedScheduleAnnotation] = s.SourceSchedule
+		} else {
+			spec.Trigger = nil
+			if len(s.SourceSchedule) > 0 {
+				spec.Trigger = &scribev1alpha1.ReplicationSourceTriggerSpec{Schedule: optionalString(s.SourceSchedule)}
+			}
+		}
+	}
+	if spec.Rsync != nil {
+		if o.changed("address") {
+			spec.Rsync.Address = optionalString(s.Address)
+		}
+		if o.changed("port") {
+			spec.Rsync.Port = optionalPort(s.Port)
+		}
+		if o.changed("path") {
+			spec.Rsync.Path = optionalString(s.Path)
+		}
+		if o.changed("source-ssh-user") {
+			spec.Rsync.SSHUser = optionalString(s.SSHUser)
+		}
+		if o.changed("source-service-type") {
+			serviceType, err := optionalServiceType(s.SourceServiceType)
+			if err != nil {
+				return fmt.Errorf("unrecognized --source-service-type %s", s.SourceServiceType)
+			}
+			spec.Rsync.ServiceType = serviceType
+		}
+		if o.changed("ssh-keys-secret") {
+			spec.Rsync.SSHKeys = optionalString(s.sshKeysSecretOptions.SSHKeysSecret)
+		}
+	}
+	if spec.Rclone != nil {
+		if o.changed("rclone-config") {
+			spec.Rclone.RcloneConfig = optionalString(s.RcloneConfig)
+		}
+		if o.changed("rclone-config-section") {
+			spec.Rclone.RcloneConfigSection = optionalString(s.RcloneConfigSection)
+		}
+		if o.changed("rclone-dest-path") {
+			spec.Rclone.RcloneDestPath = optionalString(s.RcloneDestPath)
+		}
+	}
+	if o.changed("provider") || o.changed("provider-parameters") {
+		if spec.External == nil {
+			spec.External = &scribev1alpha1.ReplicationSourceExternalSpec{}
+		}
+		if o.changed("provider") {
+			spec.External.Provider = s.Provider
+		}
+		if o.changed("provider-parameters") {
+			parameters, err := parseProviderParameters(s.ProviderParameters)
+			if err != nil {
+				return err
+			}
+			spec.External.Parameters = parameters
+		}
+		if len(spec.External.Provider) == 0 {
+			spec.External = nil
+		}
+	}
+	return nil
+}
+
+// applyUpdate prints the diff between the live and the updated object, then patches the object.
+// The diff goes to errOut when the object itself is printed with --output.
+func applyUpdate(ctx context.Context, c client.Client, p *printOptions, name string, updated, live runtime.Object, out, errOut io.Writer) error {
+	diff, err := objectDiff(name, "live", "updated", live, updated)
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		klog.V(0).Infof("%s is up to date, nothing to change", name)
+		return nil
+	}
+	diffOut := out
+	if p.outputRequested() {
+		diffOut = errOut
+	}
+	fmt.Fprint(diffOut, diff)
+	return p.patch(ctx, c, updated, live, out)
+}
+
+// optionalString returns nil for an empty flag value, to remove the field.
+func optionalString(s string) *string {
+	if len(s) == 0 {
+		return nil
+	}
+	return &s
+}
+
+// optionalPort returns nil for --port 0, to remove the field.
+func optionalPort(port int32) *int32 {
+	if port == 0 {
+		return nil
+	}
+	return &port
+}
+
+func optionalQuantity(s string) (*resource.Quantity, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	quantity, err := resource.ParseQuantity(s)
+	if err != nil {
+		return nil, err
+	}
+	return &quantity, nil
+}
+
+func optionalAccessModes(s string) ([]corev1.PersistentVolumeAccessMode, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return parseAccessMode(s)
+}
+
+func optionalServiceType(s string) (*corev1.ServiceType, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	serviceType, err := parseServiceType(s)
+	if err != nil {
+		return nil, err
+	}
+	return &serviceType, nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	scribev1alpha1 "github.com/backube/scribe/api/v1alpha1"
+)
+
+var (
+	scribeWaitLong = templates.LongDesc(`
+Wait until a ReplicationDestination, or its ReplicationSource, meets a condition:
+
+    synced        a sync completes after the command started: the last sync time advances
+    address       the ReplicationDestination publishes its address
+    latest-image  the ReplicationDestination publishes a latest image
+
+The value the condition waited for is printed once it is met: the last sync time, the address or the
+name of the latest image. The command exits with a non-zero status if the timeout expires first.
+
+With --side source, synced waits for a sync of the ReplicationSource instead.
+`)
+	scribeWaitExample = templates.Examples(`
+        # Wait up to 15 minutes for the ReplicationDestination 'dest-destination' to complete a sync.
+        scribe wait dest-destination --dest-namespace dest --for synced --timeout 15m
+
+        # Wait for the ReplicationSource replicating to 'dest-destination' to complete a sync.
+        scribe wait dest-destination --dest-namespace dest --source-namespace source --for synced --side source
+
+        # Restore the latest image once there is one.
+        SNAPSHOT=$(scribe wait dest-destination --dest-namespace dest --for latest-image)
+    `)
+
+	// waitConditions describes each value of --for
+	waitConditions = map[string]string{
+		"synced":       "complete a sync",
+		"address":      "publish its address",
+		"latest-image": "publish a latest image",
+	}
+)
+
+type waitOptions struct {
+	scribeOptions scribeOptions
+	pairOptions   pairOptions
+	For           string
+	Side          string
+	Timeout       time.Duration
+	genericclioptions.IOStreams
+}
+
+func NewWaitOptions(streams genericclioptions.IOStreams) *waitOptions {
+	return &waitOptions{
+		IOStreams: streams,
+	}
+}
+
+func NewCmdScribeWait(streams genericclioptions.IOStreams) *cobra.Command {
+	v := viper.New()
+	o := NewWaitOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "wait [NAME] --for=synced|address|latest-image [OPTIONS]",
+		Short:   i18n.T("Wait until a sync completes or a ReplicationDestination publishes its address or latest image."),
+		Long:    fmt.Sprintf(scribeWaitLong),
+		Example: fmt.Sprintf(scribeWaitExample),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Wait())
+		},
+	}
+	kcmdutil.CheckErr(o.scribeOptions.Bind(cmd, v))
+	kcmdutil.CheckErr(o.Bind(cmd, v))
+
+	return cmd
+}
+
+func (o *waitOptions) bindFlags(cmd *cobra.Command) {
+	o.pairOptions.bindFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&o.For, "for", o.For, "the condition to wait for; one of 'synced|address|latest-image'")
+	flags.StringVar(&o.Side, "side", "destination", "the object to wait for a sync of with --for synced; one of 'source|destination'")
+	flags.DurationVar(&o.Timeout, "timeout", 10*time.Minute, "how long to wait for the condition.")
+	cmd.MarkFlagRequired("for")
+}
+
+func (o *waitOptions) Bind(cmd *cobra.Command, v *viper.Viper) error {
+	bindConfig(cmd, v, func() {
+		o.bindFlags(cmd)
+	})
+	return nil
+}
+
+func (o *waitOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.scribeOptions.Complete(); err != nil {
+		return err
+	}
+	return o.pairOptions.Complete(cmd, args, o.scribeOptions.destNamespace)
+}
+
+// Validate validates wait options.
+func (o *waitOptions) Validate() error {
+	if _, ok := waitConditions[o.For]; !ok {
+		return fmt.Errorf("unrecognized --for %s; one of 'synced|address|latest-image'", o.For)
+	}
+	switch o.Side {
+	case "destination":
+	case "source":
+		if o.For != "synced" {
+			return fmt.Errorf("--for %s only applies to the destination", o.For)
+		}
+	default:
+		return fmt.Errorf("unrecognized --side %s; one of 'source|destination'", o.Side)
+	}
+	return nil
+}
+
+// Wait polls the object until the condition is met and prints the value waited for.
+func (o *waitOptions) Wait() error {
+	ctx := context.Background()
+	// sync times are stored with a precision of one second
+	start := time.Now().Truncate(time.Second)
+	if o.Side == "source" {
+		return o.waitForSourceSync(ctx, start)
+	}
+	nsName := types.NamespacedName{Namespace: o.scribeOptions.destNamespace, Name: o.pairOptions.DestName}
+	var ready func(*scribev1alpha1.ReplicationDestination) bool
+	var value func(*scribev1alpha1.ReplicationDestination) string
+	switch o.For {
+	case "synced":
+		ready = func(rd *scribev1alpha1.ReplicationDestination) bool {
+			return rd.Status != nil && syncedAfter(rd.Status.LastSyncTime, start)
+		}
+		value = func(rd *scribev1alpha1.ReplicationDestination) string {
+			return rd.Status.LastSyncTime.Format(time.RFC3339)
+		}
+	case "address":
+		ready = destinationHasAddress
+		value = func(rd *scribev1alpha1.ReplicationDestination) string {
+			return *rd.Status.Rsync.Address
+		}
+	case "latest-image":
+		ready = func(rd *scribev1alpha1.ReplicationDestination) bool {
+			return rd.Status != nil && rd.Status.LatestImage != nil && len(rd.Status.LatestImage.Name) > 0
+		}
+		value = func(rd *scribev1alpha1.ReplicationDestination) string {
+			return rd.Status.LatestImage.Name
+		}
+	}
+	klog.V(2).Infof("waiting up to %s for ReplicationDestination %s in namespace %s to %s", o.Timeout, nsName.Name, nsName.Namespace, waitConditions[o.For])
+	rd, err := waitForDestination(ctx, o.scribeOptions.DestinationClient, nsName, o.Timeout, ready)
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %s waiting for ReplicationDestination %s in namespace %s to %s", o.Timeout, nsName.Name, nsName.Namespace, waitConditions[o.For])
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(o.Out, value(rd))
+	return nil
+}
+
+func (o *waitOptions) waitForSourceSync(ctx context.Context, start time.Time) error {
+	pair, err := o.scribeOptions.getReplicationPair(ctx, o.pairOptions.DestName, o.pairOptions.SourceName)
+	if err != nil {
+		return err
+	}
+	if pair.Source == nil {
+		return fmt.Errorf("no ReplicationSource in namespace %s replicates to ReplicationDestination %s, pass --source-name", o.scribeOptions.sourceNamespace, o.pairOptions.DestName)
+	}
+	nsName := types.NamespacedName{Namespace: pair.Source.Namespace, Name: pair.Source.Name}
+	klog.V(2).Infof("waiting up to %s for ReplicationSource %s in namespace %s to %s", o.Timeout, nsName.Name, nsName.Namespace, waitConditions[o.For])
+	rs, err := waitForSource(ctx, o.scribeOptions.SourceClient, nsName, o.Timeout, func(rs *scribev1alpha1.ReplicationSource) bool {
+		return rs.Status != nil && syncedAfter(rs.Status.LastSyncTime, start)
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("timed out after %s waiting for ReplicationSource %s in namespace %s to %s", o.Timeout, nsName.Name, nsName.Namespace, waitConditions[o.For])
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(o.Out, rs.Status.LastSyncTime.Format(time.RFC3339))
+	return nil
+}
+
+// syncedAfter returns true if the last sync completed after the given time.
+func syncedAfter(lastSyncTime *metav1.Time, t time.Time) bool {
+	return lastSyncTime != nil && lastSyncTime.Time.After(t)
+}